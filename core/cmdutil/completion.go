@@ -0,0 +1,143 @@
+package cmdutil
+
+import (
+	"strings"
+
+	"github.com/jmigpin/editor/ui"
+	"github.com/jmigpin/editor/ui/tautil"
+	"github.com/jmigpin/editor/ui/tautil/textbuf"
+	"github.com/jmigpin/editor/xgbutil/xinput"
+)
+
+// Word completion: words starting with the prefix before the cursor, gathered from all rows, shown in the ui popup. The words are searched in a goroutine, typing filters the popup.
+type completion struct {
+	ed     Editorer
+	ta     *ui.TextArea
+	start  int      // prefix start index
+	prefix string   // prefix of the search
+	words  []string // nil while searching
+}
+
+var gCompletion *completion
+
+func StartCompletion(erow ERower) {
+	ta := erow.Row().TextArea
+	stopCompletion(erow.Ed())
+	start, prefix := tautil.WordPrefix(ta, ta.CursorIndex())
+	if prefix == "" {
+		return
+	}
+	c := &completion{ed: erow.Ed(), ta: ta, start: start}
+	gCompletion = c
+	c.search(prefix)
+}
+
+// Stops the completion if it's on the row.
+func StopCompletion(row *ui.Row) {
+	c := gCompletion
+	if c != nil && c.ta == row.TextArea {
+		stopCompletion(c.ed)
+	}
+}
+func stopCompletion(ed Editorer) {
+	gCompletion = nil
+	ed.UI().Popup.Hide()
+}
+
+func (c *completion) search(prefix string) {
+	c.prefix = prefix
+	c.words = nil
+
+	// snapshots are taken in the event loop, the strings are built in the goroutine
+	snap, index := c.ta.Snapshot(), c.ta.CursorIndex()
+	var snaps []*textbuf.Snapshot
+	for _, erow := range c.ed.ERows() {
+		if ta := erow.Row().TextArea; ta != c.ta {
+			snaps = append(snaps, ta.Snapshot())
+		}
+	}
+
+	go func() {
+		var others []string
+		for _, s := range snaps {
+			others = append(others, s.String())
+		}
+		words := tautil.CompletionWords(prefix, snap.String(), index, others)
+		c.ed.UI().EnqueueFunc(func() {
+			// completion could have been stopped or restarted
+			if gCompletion != c || c.prefix != prefix {
+				return
+			}
+			c.words = words
+			c.update()
+		})
+	}()
+}
+
+// Filters the words with the prefix at the cursor. Called on textarea cursor, scroll and bounds changes.
+func CompletionUpdate(erow ERower) {
+	c := gCompletion
+	if c == nil || c.ta != erow.Row().TextArea {
+		return
+	}
+	c.update()
+}
+func (c *completion) update() {
+	start, prefix := tautil.WordPrefix(c.ta, c.ta.CursorIndex())
+	if start != c.start || prefix == "" {
+		stopCompletion(c.ed)
+		return
+	}
+	if !strings.HasPrefix(prefix, c.prefix) {
+		c.search(prefix)
+		return
+	}
+	if c.words == nil {
+		return
+	}
+	var u []string
+	for _, w := range c.words {
+		if len(w) > len(prefix) && strings.HasPrefix(w, prefix) {
+			u = append(u, w)
+		}
+	}
+	if len(u) == 0 {
+		stopCompletion(c.ed)
+		return
+	}
+	c.ed.UI().Popup.Show(c.ta, c.start, u)
+}
+
+// Handles the textarea keys while on: up/down (select), return/tab (insert), escape (stop). Returns true if the key was handled.
+func CompletionKey(erow ERower, k *xinput.Key) bool {
+	c := gCompletion
+	if c == nil || c.ta != erow.Row().TextArea {
+		return false
+	}
+	if !k.Mods.ClearButtons().IsNone() {
+		return false
+	}
+	popup := c.ed.UI().Popup
+	switch k.FirstKeysym() {
+	case xinput.XKEscape:
+		stopCompletion(c.ed)
+		return true
+	case xinput.XKUp:
+		if popup.Visible() {
+			popup.Select(-1)
+			return true
+		}
+	case xinput.XKDown:
+		if popup.Visible() {
+			popup.Select(1)
+			return true
+		}
+	case xinput.XKReturn, xinput.XKTab:
+		if w, ok := popup.Selected(); ok {
+			stopCompletion(c.ed)
+			tautil.InsertCompletion(c.ta, c.start, w)
+			return true
+		}
+	}
+	return false
+}
@@ -8,6 +8,7 @@ import (
 type Editorer interface {
 	Error(error)
 	Errorf(string, ...interface{})
+	Messagef(string, ...interface{})
 	UI() *ui.UI
 
 	NewERowBeforeRow(string, *ui.Column, *ui.Row) ERower
@@ -0,0 +1,48 @@
+package cmdutil
+
+import (
+	"strings"
+
+	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/ui/tautil"
+)
+
+func Find(erow ERower, part *toolbardata.Part) {
+	opt, a := parseFindFlags(part.Args[1:], false)
+	if len(a) != 1 {
+		erow.Ed().Errorf("find: expecting 1 argument")
+		return
+	}
+	err := tautil.Find(erow.Row().TextArea, a[0].Str, opt)
+	if err != nil {
+		erow.Ed().Errorf("find: %v", err)
+	}
+}
+
+// Leading arguments made of "-" and flag letters are flags: r (regexp), c (case sensitive), i (ignore case), w (whole word). They can be combined (ex: "-rc"). Other arguments starting with "-" are search strings, and the "--" argument ends the flags. Commands that replace are case sensitive by default.
+func parseFindFlags(args []*toolbardata.Token, caseSensitive bool) (*tautil.FindOpt, []*toolbardata.Token) {
+	opt := &tautil.FindOpt{CaseSensitive: caseSensitive}
+	for len(args) > 0 {
+		s := args[0].Str
+		if s == "--" {
+			return opt, args[1:]
+		}
+		if len(s) < 2 || s[0] != '-' || strings.Trim(s[1:], "rciw") != "" {
+			break
+		}
+		for _, ru := range s[1:] {
+			switch ru {
+			case 'r':
+				opt.Regexp = true
+			case 'c':
+				opt.CaseSensitive = true
+			case 'i':
+				opt.CaseSensitive = false
+			case 'w':
+				opt.WholeWord = true
+			}
+		}
+		args = args[1:]
+	}
+	return opt, args
+}
@@ -0,0 +1,44 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/jmigpin/editor/core/toolbardata"
+)
+
+func TestParseFindFlags(t *testing.T) {
+	tests := []struct {
+		args          []string
+		caseSensitive bool
+		opt           string // regexp, case sensitive, whole word
+		rest          string
+	}{
+		{[]string{"-rw", "a"}, false, "rw", "a"},
+		{[]string{"-c", "a"}, false, "c", "a"},
+		{[]string{"a"}, true, "c", "a"},
+		{[]string{"-i", "a"}, true, "", "a"},
+		{[]string{"->"}, false, "", "->"},
+		{[]string{"-x"}, false, "", "-x"},
+		{[]string{"-r", "--", "-c"}, false, "r", "-c"},
+	}
+	for _, u := range tests {
+		var args []*toolbardata.Token
+		for _, s := range u.args {
+			args = append(args, &toolbardata.Token{Str: s})
+		}
+		opt, rest := parseFindFlags(args, u.caseSensitive)
+		s := ""
+		if opt.Regexp {
+			s += "r"
+		}
+		if opt.CaseSensitive {
+			s += "c"
+		}
+		if opt.WholeWord {
+			s += "w"
+		}
+		if s != u.opt || len(rest) != 1 || rest[0].Str != u.rest {
+			t.Fatalf("%v: %q %v", u.args, s, rest)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package cmdutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/drawutil2/loopers"
+)
+
+// Lists all the matches in the +Find row with the "file:line:col: text" format, and highlights them in the row.
+func FindAll(erow ERower, part *toolbardata.Part) {
+	ed := erow.Ed()
+	opt, a := parseFindFlags(part.Args[1:], false)
+	if len(a) != 1 {
+		ed.Errorf("findall: expecting 1 argument")
+		return
+	}
+	if erow.IsSpecialName() || erow.IsDir() {
+		ed.Errorf("findall: not a file row")
+		return
+	}
+	re, err := opt.Compile(a[0].Str)
+	if err != nil {
+		ed.Errorf("findall: %v", err)
+		return
+	}
+
+	ta := erow.Row().TextArea
+	str := ta.Str()
+	var ranges []*loopers.SelectionIndexes
+	var buf bytes.Buffer
+	line, lineStart := 1, 0
+	for _, loc := range re.FindAllStringIndex(str, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		ranges = append(ranges, &loopers.SelectionIndexes{Start: loc[0], End: loc[1]})
+
+		// line/column of the match
+		line += strings.Count(str[lineStart:loc[0]], "\n")
+		if i := strings.LastIndex(str[lineStart:loc[0]], "\n"); i >= 0 {
+			lineStart += i + 1
+		}
+		col := utf8.RuneCountInString(str[lineStart:loc[0]]) + 1
+		lineEnd := len(str)
+		if i := strings.Index(str[loc[0]:], "\n"); i >= 0 {
+			lineEnd = loc[0] + i
+		}
+		fmt.Fprintf(&buf, "%v:%d:%d: %s\n", erow.Filename(), line, col, str[lineStart:lineEnd])
+	}
+	ta.SetHighlightRanges(ranges)
+
+	if len(ranges) == 0 {
+		ed.Messagef("findall: no matches: %v", a[0].Str)
+		return
+	}
+
+	s := "+Find" // special name format
+	erow2, ok := ed.FindERow(s)
+	if !ok {
+		col, nextRow := ed.GoodColumnRowPlace()
+		erow2 = ed.NewERowBeforeRow(s, col, nextRow)
+	}
+	erow2.Row().TextArea.SetStrClear(buf.String(), true, true)
+}
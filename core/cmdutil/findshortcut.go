@@ -8,9 +8,10 @@ import (
 	"github.com/jmigpin/editor/ui/tautil"
 )
 
-// Search/add the toolbar find command and warps the pointer to it.
+// Search/add the toolbar find command and warps the pointer to it. Starts the incremental find.
 func FindShortcut(erow ERower) {
 	row := erow.Row()
+	startIncrementalFind(erow)
 
 	// check if there is a selection in the textarea
 	searchStr := ""
@@ -0,0 +1,118 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// Rules of a .gitignore file. Supports comments, negation, directory only and anchored patterns, and a leading "**/". Other uses of "**" are not supported.
+type gitIgnore struct {
+	dir   string
+	rules []*gitIgnoreRule
+}
+
+type gitIgnoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool // relative to the .gitignore directory
+}
+
+func readGitIgnore(dir string) (*gitIgnore, bool) {
+	b, err := ioutil.ReadFile(path.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil, false
+	}
+	return parseGitIgnore(dir, string(b)), true
+}
+
+// The .gitignore files of the directories above dir up to the git repository root, root first. Empty if dir is not inside a repository.
+func parentGitIgnores(dir string) []*gitIgnore {
+	var dirs []string
+	for d := dir; ; {
+		if _, err := os.Stat(path.Join(d, ".git")); err == nil {
+			break
+		}
+		p := path.Dir(d)
+		if p == d {
+			return nil // no repository
+		}
+		d = p
+		dirs = append(dirs, d)
+	}
+	var u []*gitIgnore
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if gi, ok := readGitIgnore(dirs[i]); ok {
+			u = append(u, gi)
+		}
+	}
+	return u
+}
+
+func parseGitIgnore(dir, str string) *gitIgnore {
+	gi := &gitIgnore{dir: dir}
+	for _, line := range strings.Split(str, "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := &gitIgnoreRule{}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.HasPrefix(line, "**/") {
+			line = line[len("**/"):]
+		} else if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		gi.rules = append(gi.rules, r)
+	}
+	return gi
+}
+
+// Returns if the path is ignored, and if some rule matched. The last matching rule wins.
+func (gi *gitIgnore) match(p string, isDir bool) (ignored, matched bool) {
+	rel := strings.TrimPrefix(p, gi.dir+"/")
+	for i := len(gi.rules) - 1; i >= 0; i-- {
+		r := gi.rules[i]
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.matches(rel) {
+			return !r.negate, true
+		}
+	}
+	return false, false
+}
+
+func (r *gitIgnoreRule) matches(rel string) bool {
+	if r.anchored {
+		ok, _ := path.Match(r.pattern, rel)
+		return ok
+	}
+	ok, _ := path.Match(r.pattern, path.Base(rel))
+	return ok
+}
+
+// Checks the .gitignore files from the deepest directory.
+func gitIgnored(stack []*gitIgnore, p string, isDir bool) bool {
+	for i := len(stack) - 1; i >= 0; i-- {
+		ignored, matched := stack[i].match(p, isDir)
+		if matched {
+			return ignored
+		}
+	}
+	return false
+}
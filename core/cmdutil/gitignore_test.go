@@ -0,0 +1,75 @@
+package cmdutil
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestGitIgnore(t *testing.T) {
+	gi := parseGitIgnore("/a", "# comment\n*.o\n/build/\nlogs/*.log\n!keep.o\n**/tmp\n")
+	stack := []*gitIgnore{gi}
+	tests := []struct {
+		p       string
+		isDir   bool
+		ignored bool
+	}{
+		{"/a/x.o", false, true},
+		{"/a/sub/x.o", false, true},
+		{"/a/keep.o", false, false},
+		{"/a/build", true, true},
+		{"/a/build", false, false},
+		{"/a/sub/build", true, false},
+		{"/a/logs/x.log", false, true},
+		{"/a/sub/logs/x.log", false, false},
+		{"/a/sub/tmp", true, true},
+		{"/a/x.go", false, false},
+	}
+	for _, tt := range tests {
+		if v := gitIgnored(stack, tt.p, tt.isDir); v != tt.ignored {
+			t.Errorf("%v: %v", tt.p, v)
+		}
+	}
+}
+
+func TestGitIgnoreParentDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitignoretest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sub := path.Join(dir, "sub")
+	for _, d := range []string{path.Join(dir, ".git"), sub} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		path.Join(dir, ".gitignore"): "*.o\n/sub/gen.go\n",
+		path.Join(sub, "a.go"):       "",
+		path.Join(sub, "a.o"):        "",
+		path.Join(sub, "gen.go"):     "",
+	}
+	for filename, s := range files {
+		if err := ioutil.WriteFile(filename, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// walking the sub directory uses the repository root .gitignore
+	var mu sync.Mutex
+	var u []string
+	walkTreeFiles(context.Background(), sub, func(filename string) {
+		mu.Lock()
+		defer mu.Unlock()
+		u = append(u, filename)
+	})
+	if w := []string{path.Join(sub, "a.go")}; !reflect.DeepEqual(u, w) {
+		t.Fatal(u)
+	}
+}
@@ -0,0 +1,229 @@
+package cmdutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/ui"
+)
+
+// Searches the directory tree files with a regexp. Skips .git, binary files, and .gitignore matches (also from the directories above, up to the repository root). Results are appended to the row as "path:line:col: text".
+func Grep(erow ERower, part *toolbardata.Part) {
+	row := erow.Row()
+	ed := erow.Ed()
+
+	if !erow.IsDir() {
+		ed.Errorf("grep: not a directory: %v", erow.Filename())
+		return
+	}
+	opt, a := parseFindFlags(part.Args[1:], false)
+	if len(a) != 1 {
+		ed.Errorf("grep: expecting 1 argument")
+		return
+	}
+	opt.Regexp = true
+	re, err := opt.Compile(a[0].Str)
+	if err != nil {
+		ed.Errorf("grep: %v", err)
+		return
+	}
+
+	// cancel previous context if any
+	gRowCtx.Cancel(row)
+
+	// setup context
+	ctx := gRowCtx.Add(row, context.Background())
+
+	// prepare row
+	row.Square.SetValue(ui.SquareExecuting, true)
+	row.TextArea.SetStrClear("", true, true)
+
+	g := &grep{
+		ctx:    ctx,
+		dir:    erow.Filename(),
+		re:     re,
+		append: erow.TextAreaAppendAsync,
+	}
+	go func() {
+		g.run()
+
+		// another context could be added already to the row
+		gRowCtx.ClearIfNotNewCtx(row, ctx, func() {
+			row.Square.SetValue(ui.SquareExecuting, false)
+			row.Square.SetValue(ui.SquareEdited, false)
+			row.Col.Cols.Layout.UI.RequestPaint()
+		})
+	}()
+}
+
+type grep struct {
+	ctx    context.Context
+	dir    string
+	re     *regexp.Regexp
+	append func(string)
+
+	nmatches int
+	nfiles   int
+}
+
+func (g *grep) run() {
+	results := make(chan *grepResult, 64)
+	go func() {
+		defer close(results)
+		walkTreeFiles(g.ctx, g.dir, func(filename string) {
+			if r, ok := g.grepFile(filename); ok {
+				results <- r
+			}
+		})
+	}()
+
+	for r := range results {
+		g.nmatches += r.n
+		g.nfiles++
+		g.append(r.str)
+	}
+
+	if g.canceled() {
+		g.append("# grep: canceled\n")
+		return
+	}
+	g.append(fmt.Sprintf("# grep: %d matches in %d files\n", g.nmatches, g.nfiles))
+}
+
+func (g *grep) canceled() bool {
+	select {
+	case <-g.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Calls fn concurrently for each regular file of the tree. Skips .git and .gitignore matches, including the .gitignore files above dir in the repository.
+func walkTreeFiles(ctx context.Context, dir string, fn func(filename string)) {
+	files := make(chan string, 64)
+	go func() {
+		defer close(files)
+		walkTree(ctx, dir, parentGitIgnores(dir), files)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filename := range files {
+				fn(filename)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func walkTree(ctx context.Context, dir string, ignores []*gitIgnore, files chan<- string) {
+	if ctx.Err() != nil {
+		return
+	}
+	if gi, ok := readGitIgnore(dir); ok {
+		ignores = append(ignores[:len(ignores):len(ignores)], gi)
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	fis, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return
+	}
+	for _, fi := range fis {
+		p := path.Join(dir, fi.Name())
+		if fi.IsDir() {
+			if fi.Name() == ".git" || gitIgnored(ignores, p, true) {
+				continue
+			}
+			walkTree(ctx, p, ignores, files)
+			continue
+		}
+		if !fi.Mode().IsRegular() || gitIgnored(ignores, p, false) {
+			continue
+		}
+		select {
+		case files <- p:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type grepResult struct {
+	str string
+	n   int
+}
+
+func (g *grep) grepFile(filename string) (*grepResult, bool) {
+	if g.canceled() {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil || isBinary(b) {
+		return nil, false
+	}
+	locs := g.re.FindAllIndex(b, -1)
+	if len(locs) == 0 {
+		return nil, false
+	}
+
+	rel := strings.TrimPrefix(filename, g.dir+"/")
+	var buf bytes.Buffer
+	n := 0
+	line, lineStart := 1, 0
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue
+		}
+		n++
+		line += bytes.Count(b[lineStart:loc[0]], []byte("\n"))
+		if i := bytes.LastIndexByte(b[lineStart:loc[0]], '\n'); i >= 0 {
+			lineStart += i + 1
+		}
+		col := utf8.RuneCount(b[lineStart:loc[0]]) + 1
+		lineEnd := len(b)
+		if i := bytes.IndexByte(b[loc[0]:], '\n'); i >= 0 {
+			lineEnd = loc[0] + i
+		}
+		text := b[lineStart:lineEnd]
+		if len(text) > grepMaxLineLen {
+			text = text[:grepMaxLineLen]
+		}
+		fmt.Fprintf(&buf, "%s:%d:%d: %s\n", rel, line, col, text)
+	}
+	if n == 0 {
+		return nil, false
+	}
+	return &grepResult{str: buf.String(), n: n}, true
+}
+
+// Long lines (ex: minified files) are cut in the results.
+var grepMaxLineLen = 256
+
+const binarySampleLen = 8000
+
+// Files with a zero byte in the first binarySampleLen bytes are considered binary.
+func isBinary(b []byte) bool {
+	n := len(b)
+	if n > binarySampleLen {
+		n = binarySampleLen
+	}
+	return bytes.IndexByte(b[:n], 0) >= 0
+}
@@ -0,0 +1,169 @@
+package cmdutil
+
+import (
+	"image"
+
+	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/ui"
+	"github.com/jmigpin/editor/ui/tautil"
+	"github.com/jmigpin/editor/xgbutil/xinput"
+	"golang.org/x/image/math/fixed"
+)
+
+// Incremental find: while on, typing after "Find" in the toolbar searches the textarea. Started by the find shortcut, stops when the pointer leaves the toolbar, the textarea is edited, or the Find part is removed.
+type incFind struct {
+	start   incFindPos
+	offsetY fixed.Int26_6
+	last    incFindPos // set by the find, the user didn't move away if still the same
+	entered bool       // pointer was in the toolbar, the warp is done
+}
+
+type incFindPos struct {
+	cursorIndex int
+	selIndex    int
+	selOn       bool
+}
+
+func textAreaPos(ta *ui.TextArea) incFindPos {
+	p := incFindPos{cursorIndex: ta.CursorIndex()}
+	if ta.SelectionOn() {
+		p.selIndex, p.selOn = ta.SelectionIndex(), true
+	}
+	return p
+}
+
+var gIncFind = make(map[*ui.Row]*incFind)
+
+func startIncrementalFind(erow ERower) {
+	ta := erow.Row().TextArea
+	p := textAreaPos(ta)
+	gIncFind[erow.Row()] = &incFind{start: p, offsetY: ta.OffsetY(), last: p}
+}
+func StopIncrementalFind(row *ui.Row) {
+	delete(gIncFind, row)
+}
+
+// Stops the incremental find of the rows whose toolbar the pointer left (keys go elsewhere), or on a button press outside the toolbar.
+func IncrementalFindPointer(p *image.Point, press bool) {
+	for row, s := range gIncFind {
+		if p.In(*row.Toolbar.Bounds()) {
+			s.entered = true
+		} else if s.entered || press {
+			StopIncrementalFind(row)
+		}
+	}
+}
+
+// Searches from the position where the incremental find started. Called on toolbar changes.
+func IncrementalFindUpdate(erow ERower) {
+	s, ok := gIncFind[erow.Row()]
+	if !ok {
+		return
+	}
+	part, ok := incFindPart(erow)
+	if !ok {
+		StopIncrementalFind(erow.Row())
+		return
+	}
+	opt, a := parseFindFlags(part.Args[1:], false)
+	if len(a) == 0 {
+		s.restore(erow.Row().TextArea)
+		return
+	}
+	// incomplete regexps are expected while typing
+	re, err := opt.Compile(incFindStr(erow, a))
+	if err != nil {
+		return
+	}
+	ta := erow.Row().TextArea
+	if textAreaPos(ta) != s.last {
+		// the user moved away from the last match
+		StopIncrementalFind(erow.Row())
+		return
+	}
+	index := s.start.cursorIndex
+	if s.start.selOn && s.start.selIndex < index {
+		index = s.start.selIndex
+	}
+	if tautil.FindRegexpFromIndex(ta, re, index, false) {
+		s.last = textAreaPos(ta)
+	} else {
+		s.restore(ta)
+	}
+}
+
+// Handles toolbar keys while on: return (next match), shift+return (previous match), escape (restore position). Returns true if the key was handled.
+func IncrementalFindKey(erow ERower, k *xinput.Key) bool {
+	s, ok := gIncFind[erow.Row()]
+	if !ok {
+		return false
+	}
+	ta := erow.Row().TextArea
+	mods := k.Mods.ClearButtons()
+	switch k.FirstKeysym() {
+	case xinput.XKEscape:
+		s.restore(ta)
+		StopIncrementalFind(erow.Row())
+		return true
+	case xinput.XKReturn:
+		part, ok := incFindPart(erow)
+		if !ok {
+			return false
+		}
+		opt, a := parseFindFlags(part.Args[1:], false)
+		if len(a) == 0 {
+			return true
+		}
+		re, err := opt.Compile(incFindStr(erow, a))
+		if err != nil {
+			erow.Ed().Errorf("find: %v", err)
+			return true
+		}
+		switch {
+		case mods.IsShift():
+			index := ta.CursorIndex()
+			if ta.SelectionOn() {
+				index, _ = tautil.SelectionStringIndexes(ta)
+			}
+			tautil.FindRegexpFromIndex(ta, re, index, true)
+		default:
+			tautil.FindRegexpFromIndex(ta, re, ta.CursorIndex(), false)
+		}
+		s.last = textAreaPos(ta)
+		return true
+	}
+	return false
+}
+
+// Restores the position where the find started, unless the user moved away from the position set by the find.
+func (s *incFind) restore(ta *ui.TextArea) {
+	if textAreaPos(ta) != s.last {
+		return
+	}
+	if s.start.selOn {
+		ta.SetSelection(s.start.selIndex, s.start.cursorIndex)
+	} else {
+		ta.SetSelectionOff()
+		ta.SetCursorIndex(s.start.cursorIndex)
+	}
+	ta.SetOffsetY(s.offsetY)
+	s.last = s.start
+}
+
+// Find part with the toolbar cursor.
+func incFindPart(erow ERower) (*toolbardata.Part, bool) {
+	td := erow.ToolbarData()
+	part, ok := td.GetPartAtIndex(erow.Row().Toolbar.CursorIndex())
+	if !ok || len(part.Args) == 0 || part.Args[0].Str != "Find" {
+		return nil, false
+	}
+	return part, true
+}
+
+// Multiple arguments are searched as typed (ex: "Find a b" searches "a b").
+func incFindStr(erow ERower, a []*toolbardata.Token) string {
+	if len(a) == 1 {
+		return a[0].Str
+	}
+	return erow.ToolbarData().Str[a[0].S:a[len(a)-1].E]
+}
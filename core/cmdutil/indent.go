@@ -0,0 +1,46 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/ui/tautil"
+)
+
+// Re-indents the selection lines, or the whole text, with tabs (n spaces per tab).
+func IndentToTabs(erow ERower, part *toolbardata.Part) {
+	n, err := indentArg(part)
+	if err != nil {
+		erow.Ed().Error(err)
+		return
+	}
+	ta := erow.Row().TextArea
+	tautil.IndentToTabs(ta, n)
+	ta.SetIndentUnit("\t")
+}
+
+// Re-indents the selection lines, or the whole text, with spaces (tab stops every n spaces).
+func IndentToSpaces(erow ERower, part *toolbardata.Part) {
+	n, err := indentArg(part)
+	if err != nil {
+		erow.Ed().Error(err)
+		return
+	}
+	ta := erow.Row().TextArea
+	tautil.IndentToSpaces(ta, n)
+	ta.SetIndentUnit(strings.Repeat(" ", n))
+}
+
+func indentArg(part *toolbardata.Part) (int, error) {
+	a := part.Args[1:]
+	if len(a) != 1 {
+		return 0, fmt.Errorf("%v: expecting the number of spaces", part.Args[0].Str)
+	}
+	n, err := strconv.Atoi(a[0].Str)
+	if err != nil || n < 1 || n > 16 {
+		return 0, fmt.Errorf("%v: bad number of spaces: %v", part.Args[0].Str, a[0].Str)
+	}
+	return n, nil
+}
@@ -0,0 +1,40 @@
+package cmdutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jmigpin/editor/ui"
+)
+
+// Lists the active key bindings, and the textarea actions without bindings.
+func ListBindings(ed Editorer) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	bound := map[string]bool{}
+	for _, b := range ui.TextAreaKeymap.Bindings() {
+		fmt.Fprintf(tw, "%v\t%v\n", b.Seq, b.Action)
+		bound[b.Action] = true
+	}
+	tw.Flush()
+
+	var u []string
+	for _, a := range ui.TextAreaActions() {
+		if !bound[a] {
+			u = append(u, a)
+		}
+	}
+	if len(u) > 0 {
+		fmt.Fprintf(&buf, "\nunbound actions:\n%v\n", strings.Join(u, "\n"))
+	}
+
+	s := "+Bindings"
+	erow, ok := ed.FindERow(s)
+	if !ok {
+		col, nextRow := ed.GoodColumnRowPlace()
+		erow = ed.NewERowBeforeRow(s, col, nextRow)
+	}
+	erow.Row().TextArea.SetStrClear(buf.String(), false, false)
+}
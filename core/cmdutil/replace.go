@@ -1,19 +1,21 @@
 package cmdutil
 
 import (
-	"fmt"
-
 	"github.com/jmigpin/editor/core/toolbardata"
 	"github.com/jmigpin/editor/ui/tautil"
 )
 
 func Replace(erow ERower, part *toolbardata.Part) {
-	a := part.Args[1:]
+	opt, a := parseFindFlags(part.Args[1:], true)
 	if len(a) != 2 {
-		err := fmt.Errorf("replace: expecting 2 arguments")
-		erow.Ed().Error(err)
+		erow.Ed().Errorf("replace: expecting 2 arguments")
 		return
 	}
 	old, new := a[0].Str, a[1].Str
-	tautil.Replace(erow.Row().TextArea, old, new)
+	n, err := tautil.Replace(erow.Row().TextArea, old, new, opt)
+	if err != nil {
+		erow.Ed().Errorf("replace: %v", err)
+		return
+	}
+	erow.Ed().Messagef("replace: %d replacements", n)
 }
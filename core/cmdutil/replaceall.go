@@ -0,0 +1,268 @@
+package cmdutil
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jmigpin/editor/core/fileformat"
+	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/ui"
+	"github.com/jmigpin/editor/ui/tautil"
+)
+
+// Replaces a regexp in the directory tree files. Shows a unified diff of the changes in the +ReplaceAll row, that are only written with the Apply command.
+func ReplaceAll(erow ERower, part *toolbardata.Part) {
+	ed := erow.Ed()
+
+	if !erow.IsDir() {
+		ed.Errorf("replaceall: not a directory: %v", erow.Filename())
+		return
+	}
+	opt, a := parseFindFlags(part.Args[1:], true)
+	if len(a) != 2 {
+		ed.Errorf("replaceall: expecting 2 arguments")
+		return
+	}
+	opt.Regexp = true
+	re, err := opt.Compile(a[0].Str)
+	if err != nil {
+		ed.Errorf("replaceall: %v", err)
+		return
+	}
+	new := a[1].Str
+
+	// content of open rows, the diff is made against what is being edited
+	open := make(map[string]string)
+	for _, e := range ed.ERows() {
+		if !e.IsSpecialName() && !e.IsDir() {
+			open[e.Filename()] = e.Row().TextArea.Str()
+		}
+	}
+
+	s := "+ReplaceAll" // special name format
+	erow2, ok := ed.FindERow(s)
+	if !ok {
+		col, nextRow := ed.GoodColumnRowPlace()
+		erow2 = ed.NewERowBeforeRow(s+" | Apply", col, nextRow)
+	}
+	row2 := erow2.Row()
+
+	// cancel previous context if any
+	gRowCtx.Cancel(row2)
+
+	// setup context
+	ctx := gRowCtx.Add(row2, context.Background())
+
+	// prepare row
+	row2.Square.SetValue(ui.SquareExecuting, true)
+	row2.TextArea.SetStrClear("", true, true)
+
+	dir := erow.Filename()
+	ra := &replaceAll{}
+	gReplaceAll[row2] = ra
+
+	// appends in the event loop, unless a new run replaced this one
+	appendStr := func(s string) {
+		ed.UI().EnqueueFunc(func() {
+			if gReplaceAll[row2] == ra {
+				ed.UI().TextAreaAppend(row2.TextArea, s)
+			}
+		})
+	}
+
+	go func() {
+		var mu sync.Mutex
+		walkTreeFiles(ctx, dir, func(filename string) {
+			if ctx.Err() != nil {
+				return
+			}
+			var f *replaceAllFile
+			if str, ok := open[filename]; ok {
+				f = &replaceAllFile{filename: filename, old: str}
+			} else {
+				f, ok = replaceAllReadFile(filename)
+				if !ok {
+					return
+				}
+			}
+			f.new, f.n = tautil.ReplaceString(f.old, re, new, true)
+			if f.n == 0 {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ra.files = append(ra.files, f)
+		})
+
+		// output sorted by filename
+		sort.Slice(ra.files, func(i, j int) bool {
+			return ra.files[i].filename < ra.files[j].filename
+		})
+		n := 0
+		for _, f := range ra.files {
+			if ctx.Err() != nil {
+				break
+			}
+			n += f.n
+			rel := strings.TrimPrefix(f.filename, dir+"/")
+			appendStr(unifiedDiff("a/"+rel, "b/"+rel, f.old, f.new))
+		}
+		if ctx.Err() != nil {
+			appendStr("# replaceall: canceled\n")
+		} else {
+			ra.setDone()
+			appendStr(fmt.Sprintf("# replaceall: %d replacements in %d files, run Apply to write\n", n, len(ra.files)))
+		}
+
+		// another context could be added already to the row
+		gRowCtx.ClearIfNotNewCtx(row2, ctx, func() {
+			row2.Square.SetValue(ui.SquareExecuting, false)
+			row2.Square.SetValue(ui.SquareEdited, false)
+			row2.Col.Cols.Layout.UI.RequestPaint()
+		})
+	}()
+}
+
+// Writes the changes previewed in the +ReplaceAll row. Open rows are edited in the textarea (can be undone and need to be saved), other files are written to disk. Files changed since the preview are skipped.
+func ReplaceAllApply(erow ERower) {
+	ed := erow.Ed()
+	ra, ok := gReplaceAll[erow.Row()]
+	if !ok || !ra.isDone() {
+		ed.Errorf("apply: no replaceall changes in this row")
+		return
+	}
+	delete(gReplaceAll, erow.Row())
+
+	var nrows, nfiles int
+	var skipped []string
+	for _, f := range ra.files {
+		// open rows might have been opened/closed since the preview
+		if e, ok := ed.FindERow(f.filename); ok && !e.IsDir() {
+			ta := e.Row().TextArea
+			if ta.Str() != f.old {
+				skipped = append(skipped, f.filename)
+				continue
+			}
+			editTextAreaStr(ta, f.new)
+			nrows++
+			continue
+		}
+		changed, err := replaceAllWriteFile(f)
+		if err != nil {
+			ed.Errorf("apply: %v", err)
+			continue
+		}
+		if changed {
+			skipped = append(skipped, f.filename)
+			continue
+		}
+		nfiles++
+	}
+
+	erow.Row().TextArea.SetStrClear("", true, true)
+	ed.Messagef("apply: %d rows edited, %d files written", nrows, nfiles)
+	if len(skipped) > 0 {
+		ed.Messagef("apply: changed since the preview, skipped:\n%v", strings.Join(skipped, "\n"))
+	}
+}
+
+// Content decoded like the rows (encoding, line endings and bom), binary files are skipped.
+func replaceAllReadFile(filename string) (*replaceAllFile, bool) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, false
+	}
+	str, ff, err := fileformat.Decode(string(b), nil)
+	if err != nil {
+		return nil, false
+	}
+	n := len(str)
+	if n > binarySampleLen {
+		n = binarySampleLen
+	}
+	if isBinary([]byte(str[:n])) {
+		return nil, false
+	}
+	return &replaceAllFile{filename: filename, old: str, format: ff}, true
+}
+
+// Doesn't write if the file changed since the preview.
+func replaceAllWriteFile(f *replaceAllFile) (changed bool, _ error) {
+	fi, err := os.Stat(f.filename)
+	if err != nil {
+		return false, err
+	}
+	b, err := ioutil.ReadFile(f.filename)
+	if err != nil {
+		return false, err
+	}
+	str, ff, err := fileformat.Decode(string(b), f.format.Encoding())
+	if err != nil {
+		return false, err
+	}
+	if str != f.old || ff != f.format {
+		return true, nil
+	}
+	str, err = ff.Encode(f.new)
+	if err != nil {
+		return false, err
+	}
+	return false, ioutil.WriteFile(f.filename, []byte(str), fi.Mode())
+}
+
+// Edits only the range that differs, keeping the cursor position and the undo history.
+func editTextAreaStr(ta *ui.TextArea, s string) {
+	old := ta.Str()
+	p := 0
+	for p < len(old) && p < len(s) && old[p] == s[p] {
+		p++
+	}
+	q := 0
+	for q < len(old)-p && q < len(s)-p && old[len(old)-1-q] == s[len(s)-1-q] {
+		q++
+	}
+	ta.EditOpen()
+	if p < len(old)-q {
+		ta.EditDelete(p, len(old)-q)
+	}
+	if p < len(s)-q {
+		ta.EditInsert(p, s[p:len(s)-q])
+	}
+	ta.EditClose()
+}
+
+type replaceAll struct {
+	sync.Mutex
+	files []*replaceAllFile
+	done  bool
+}
+
+func (ra *replaceAll) setDone() {
+	ra.Lock()
+	defer ra.Unlock()
+	ra.done = true
+}
+func (ra *replaceAll) isDone() bool {
+	ra.Lock()
+	defer ra.Unlock()
+	return ra.done
+}
+
+type replaceAllFile struct {
+	filename string
+	old, new string
+	n        int
+	format   fileformat.Format // files that are not open
+}
+
+// Pending changes by preview row.
+var gReplaceAll = make(map[*ui.Row]*replaceAll)
+
+func ClearReplaceAll(row *ui.Row) {
+	delete(gReplaceAll, row)
+}
@@ -0,0 +1,44 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"testing"
+
+	"github.com/jmigpin/editor/ui/tautil"
+)
+
+func TestReplaceAllFileCRLF(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replacealltest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := path.Join(dir, "a.txt")
+	if err := ioutil.WriteFile(filename, []byte("a1\r\nb1\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// edited like an open row, without the "\r"
+	f, ok := replaceAllReadFile(filename)
+	if !ok || f.old != "a1\nb1\n" {
+		t.Fatalf("%v %+v", ok, f)
+	}
+	f.new, f.n = tautil.ReplaceString(f.old, regexp.MustCompile(`(?m)1$`), "2", true)
+	if f.n != 2 {
+		t.Fatalf("%q", f.new)
+	}
+	changed, err := replaceAllWriteFile(f)
+	if err != nil || changed {
+		t.Fatal(changed, err)
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "a2\r\nb2\r\n" {
+		t.Fatalf("%q", b)
+	}
+}
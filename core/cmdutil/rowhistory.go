@@ -0,0 +1,92 @@
+package cmdutil
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+
+	"github.com/jmigpin/editor/ui"
+	"github.com/jmigpin/editor/ui/tautil"
+)
+
+// Textarea undo history. Only restored if the content is the same as when it was kept.
+type RowHistory struct {
+	Hash    string
+	History *tautil.EditHistory
+}
+
+func NewRowHistory(ta *ui.TextArea) *RowHistory {
+	return &RowHistory{Hash: strHash(ta.Str()), History: ta.EditHistory()}
+}
+func (rh *RowHistory) restore(ta *ui.TextArea) bool {
+	if rh.Hash != strHash(ta.Str()) {
+		return false
+	}
+	ta.SetEditHistory(rh.History)
+	return true
+}
+
+func strHash(s string) string {
+	h := sha1.Sum([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+// Histories are saved per file in a directory next to the sessions file.
+func rowHistoryFilename(filename string) string {
+	home := os.Getenv("HOME")
+	return path.Join(home, ".editor_sessions_history", strHash(filename)+".json")
+}
+
+func hasRowHistoryFile(erow ERower) bool {
+	return !erow.IsSpecialName() && !erow.IsDir() && erow.Filename() != ""
+}
+
+func saveRowHistory(erow ERower) error {
+	if !hasRowHistoryFile(erow) {
+		return nil
+	}
+	filename := rowHistoryFilename(erow.Filename())
+	if err := os.MkdirAll(path.Dir(filename), 0755); err != nil {
+		return err
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	f, err := os.OpenFile(filename, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rh := NewRowHistory(erow.Row().TextArea)
+	return json.NewEncoder(f).Encode(rh)
+}
+func loadRowHistory(erow ERower) error {
+	if !hasRowHistoryFile(erow) {
+		return nil
+	}
+	f, err := os.Open(rowHistoryFilename(erow.Filename()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	rh := &RowHistory{}
+	if err := json.NewDecoder(f).Decode(rh); err != nil {
+		return err
+	}
+	_ = rh.restore(erow.Row().TextArea)
+	return nil
+}
+
+// Selects the branch followed by redo when there were edits after an undo.
+func RedoBranch(erow ERower, d int) {
+	h := erow.Row().TextArea.EditHistory()
+	i, n := h.SelectRedoBranch(d)
+	if n == 0 {
+		erow.Ed().Errorf("redobranch: nothing to redo")
+		return
+	}
+	erow.Ed().Messagef("redo branch %d/%d", i+1, n)
+}
@@ -8,6 +8,9 @@ type RowState struct {
 	TbCursorIndex int
 	TaCursorIndex int
 	TaOffsetIndex int
+
+	// kept in memory for reopenrow, sessions save it in its own file
+	TaHistory *RowHistory `json:"-"`
 }
 
 func NewRowState(row *ui.Row) *RowState {
@@ -16,6 +19,7 @@ func NewRowState(row *ui.Row) *RowState {
 		TbCursorIndex: row.Toolbar.CursorIndex(),
 		TaCursorIndex: row.TextArea.CursorIndex(),
 		TaOffsetIndex: row.TextArea.OffsetIndex(),
+		TaHistory:     NewRowHistory(row.TextArea),
 	}
 }
 func NewERowFromRowState(ed Editorer, state *RowState, col *ui.Column, nextRow *ui.Row) ERower {
@@ -27,6 +31,9 @@ func NewERowFromRowState(ed Editorer, state *RowState, col *ui.Column, nextRow *
 		ed.Error(err)
 		return erow
 	}
+	if state.TaHistory != nil {
+		_ = state.TaHistory.restore(row.TextArea)
+	}
 	row.TextArea.SetCursorIndex(state.TaCursorIndex)
 	row.TextArea.SetOffsetIndex(state.TaOffsetIndex)
 	return erow
@@ -88,7 +88,10 @@ func (s *Session) restore(ed Editorer) {
 	for i, c := range s.Columns {
 		col := columns[i]
 		for _, r := range c.Rows {
-			_ = NewERowFromRowState(ed, r, col, nil)
+			erow := NewERowFromRowState(ed, r, col, nil)
+			if err := loadRowHistory(erow); err != nil {
+				ed.Error(err)
+			}
 		}
 	}
 }
@@ -154,6 +157,12 @@ func saveSession(ed Editorer, part *toolbardata.Part, filename string) error {
 	if err != nil {
 		return err
 	}
+	// save undo histories
+	for _, erow := range ed.ERows() {
+		if err := saveRowHistory(erow); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,21 @@
+package cmdutil
+
+import (
+	"strings"
+
+	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/ui"
+)
+
+func SetTheme(ed Editorer, part *toolbardata.Part) {
+	if len(part.Args) != 2 {
+		ed.Errorf("missing theme name: %v", strings.Join(ui.ThemeNames(), ", "))
+		return
+	}
+	if err := ui.SetTheme(part.Args[1].Str); err != nil {
+		ed.Error(err)
+		return
+	}
+	// colors are read at paint time
+	ed.UI().Layout.C.NeedPaint()
+}
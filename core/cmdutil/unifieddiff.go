@@ -0,0 +1,260 @@
+package cmdutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Unified diff of two strings by lines, with the "---"/"+++" headers. Returns an empty string if there are no differences.
+func unifiedDiff(name1, name2, s1, s2 string) string {
+	a, b := splitLines(s1), splitLines(s2)
+	ops := diffLines(a, b)
+	hunks := diffHunks(ops, unifiedDiffContext)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", name1, name2)
+	for _, h := range hunks {
+		h.write(&buf, a, b)
+	}
+	return buf.String()
+}
+
+var unifiedDiffContext = 3
+
+// Lines keep the newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	u := strings.SplitAfter(s, "\n")
+	if u[len(u)-1] == "" {
+		u = u[:len(u)-1]
+	}
+	return u
+}
+
+type diffOpType int
+
+const (
+	diffEqual diffOpType = iota
+	diffDelete
+	diffInsert
+)
+
+// Line i of a (delete, equal) or line j of b (insert).
+type diffOp struct {
+	t    diffOpType
+	i, j int
+}
+
+// Myers diff algorithm, linear space version: the middle of an optimal path splits the problem in two. Returns the edit script to transform a into b.
+func diffLines(a, b []string) []*diffOp {
+	var ops []*diffOp
+	diffRange(a, b, 0, len(a), 0, len(b), &ops)
+	return ops
+}
+
+// Appends the ops of a[a0:a1] against b[b0:b1].
+func diffRange(a, b []string, a0, a1, b0, b1 int, ops *[]*diffOp) {
+	// common prefix
+	for a0 < a1 && b0 < b1 && a[a0] == b[b0] {
+		*ops = append(*ops, &diffOp{t: diffEqual, i: a0, j: b0})
+		a0++
+		b0++
+	}
+	// common suffix, appended at the end
+	suffix := 0
+	for a0 < a1 && b0 < b1 && a[a1-1] == b[b1-1] {
+		a1--
+		b1--
+		suffix++
+	}
+
+	x, y, ok := 0, 0, false
+	if a0 < a1 && b0 < b1 {
+		x, y, ok = diffMiddle(a, b, a0, a1, b0, b1)
+	}
+	if ok {
+		diffRange(a, b, a0, x, b0, y, ops)
+		diffRange(a, b, x, a1, y, b1, ops)
+	} else {
+		// no common lines
+		for i := a0; i < a1; i++ {
+			*ops = append(*ops, &diffOp{t: diffDelete, i: i, j: b0})
+		}
+		for j := b0; j < b1; j++ {
+			*ops = append(*ops, &diffOp{t: diffInsert, i: a1, j: j})
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		*ops = append(*ops, &diffOp{t: diffEqual, i: a1 + k, j: b1 + k})
+	}
+}
+
+// Point where the forward path from the start and the reverse path from the end overlap. Returns false if the ranges have no common lines.
+func diffMiddle(a, b []string, a0, a1, b0, b1 int) (int, int, bool) {
+	n, m := a1-a0, b1-b0
+	max := (n + m + 1) / 2
+	off := max + 1
+	vf := make([]int, 2*max+3) // forward: furthest x by diagonal k
+	vr := make([]int, 2*max+3) // reverse: furthest x from the end by diagonal k
+	for i := range vf {
+		vf[i], vr[i] = -1, -1
+	}
+	vf[off+1], vr[off+1] = 0, 0
+	delta := n - m
+	odd := delta%2 != 0
+	// diagonals that went outside the ranges are skipped
+	var fs, fe, rs, re int
+
+	for d := 0; d < max; d++ {
+		for k := -d + fs; k <= d-fe; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[a0+x] == b[b0+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			switch {
+			case x > n:
+				fe += 2
+			case y > m:
+				fs += 2
+			case odd:
+				if kr := off + delta - k; kr >= 0 && kr < len(vr) && vr[kr] != -1 {
+					if x >= n-vr[kr] {
+						return a0 + x, b0 + y, true
+					}
+				}
+			}
+		}
+		for k := -d + rs; k <= d-re; k += 2 {
+			var x int
+			if k == -d || (k != d && vr[off+k-1] < vr[off+k+1]) {
+				x = vr[off+k+1]
+			} else {
+				x = vr[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[a1-1-x] == b[b1-1-y] {
+				x++
+				y++
+			}
+			vr[off+k] = x
+			switch {
+			case x > n:
+				re += 2
+			case y > m:
+				rs += 2
+			case !odd:
+				if kf := off + delta - k; kf >= 0 && kf < len(vf) && vf[kf] != -1 {
+					xf := vf[kf]
+					yf := off + xf - kf
+					if xf >= n-x {
+						return a0 + xf, b0 + yf, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+type diffHunk struct {
+	ops []*diffOp
+}
+
+// Groups the changes with n lines of context. Changes closer then 2*n lines share a hunk.
+func diffHunks(ops []*diffOp, n int) []*diffHunk {
+	var hunks []*diffHunk
+	i := 0
+	for i < len(ops) {
+		// next change
+		for i < len(ops) && ops[i].t == diffEqual {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		start := i - n
+		if start < 0 {
+			start = 0
+		}
+		// extend while the gap of equal lines is small
+		end := i
+		for end < len(ops) {
+			if ops[end].t != diffEqual {
+				end++
+				continue
+			}
+			k := end
+			for k < len(ops) && ops[k].t == diffEqual {
+				k++
+			}
+			if k == len(ops) || k-end > 2*n {
+				break
+			}
+			end = k
+		}
+		stop := end + n
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+		hunks = append(hunks, &diffHunk{ops: ops[start:stop]})
+		i = end
+	}
+	return hunks
+}
+
+func (h *diffHunk) write(buf *bytes.Buffer, a, b []string) {
+	// line ranges (1-based, start is the line before if the range is empty)
+	i0, j0 := h.ops[0].i, h.ops[0].j
+	na, nb := 0, 0
+	for _, op := range h.ops {
+		switch op.t {
+		case diffEqual:
+			na++
+			nb++
+		case diffDelete:
+			na++
+		case diffInsert:
+			nb++
+		}
+	}
+	if na > 0 {
+		i0++
+	}
+	if nb > 0 {
+		j0++
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", i0, na, j0, nb)
+
+	line := func(prefix, s string) {
+		buf.WriteString(prefix)
+		buf.WriteString(s)
+		if !strings.HasSuffix(s, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	for _, op := range h.ops {
+		switch op.t {
+		case diffEqual:
+			line(" ", a[op.i])
+		case diffDelete:
+			line("-", a[op.i])
+		case diffInsert:
+			line("+", b[op.j])
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package cmdutil
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	s1 := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	s2 := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk"
+	s := unifiedDiff("a/f", "b/f", s1, s2)
+	e := "--- a/f\n+++ b/f\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n\\ No newline at end of file\n"
+	if s != e {
+		t.Fatalf("%q", s)
+	}
+}
+
+func TestUnifiedDiffEqual(t *testing.T) {
+	if s := unifiedDiff("a", "b", "", ""); s != "" {
+		t.Fatalf("%q", s)
+	}
+	if s := unifiedDiff("a", "b", "x\n", "x\n"); s != "" {
+		t.Fatalf("%q", s)
+	}
+}
+
+func TestUnifiedDiffEmpty(t *testing.T) {
+	s := unifiedDiff("a", "b", "", "x\ny\n")
+	e := "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n"
+	if s != e {
+		t.Fatalf("%q", s)
+	}
+}
+
+func TestDiffLinesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		u := make([]string, r.Intn(30))
+		for i := range u {
+			u[i] = string('a' + rune(r.Intn(4)))
+		}
+		return u
+	}
+	for n := 0; n < 500; n++ {
+		a, b := gen(), gen()
+		ops := diffLines(a, b)
+		// ops rebuild b from a, in order
+		var a2, b2 []string
+		edits := 0
+		for _, op := range ops {
+			switch op.t {
+			case diffEqual:
+				if a[op.i] != b[op.j] {
+					t.Fatal("equal op with different lines")
+				}
+				a2 = append(a2, a[op.i])
+				b2 = append(b2, b[op.j])
+			case diffDelete:
+				a2 = append(a2, a[op.i])
+				edits++
+			case diffInsert:
+				b2 = append(b2, b[op.j])
+				edits++
+			}
+		}
+		if strings.Join(a2, "") != strings.Join(a, "") || strings.Join(b2, "") != strings.Join(b, "") {
+			t.Fatalf("%v %v: bad script", a, b)
+		}
+		// minimal: edits are the lines outside the longest common subsequence
+		if e := len(a) + len(b) - 2*lcsLen(a, b); edits != e {
+			t.Fatalf("%v %v: %v edits, expecting %v", a, b, edits, e)
+		}
+	}
+}
+
+func lcsLen(a, b []string) int {
+	u := make([][]int, len(a)+1)
+	for i := range u {
+		u[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				u[i][j] = u[i+1][j+1] + 1
+			case u[i+1][j] > u[i][j+1]:
+				u[i][j] = u[i+1][j]
+			default:
+				u[i][j] = u[i][j+1]
+			}
+		}
+	}
+	return u[0][0]
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/jmigpin/editor/drawutil2"
+	"github.com/jmigpin/editor/drawutil2/loopers"
+	"github.com/jmigpin/editor/ui"
+)
+
+// Config file options. Unset options keep the command line values, and flags given in the command line take precedence over the config.
+type Config struct {
+	Font           *string
+	FontSize       *float64
+	DPI            *float64
+	ScrollbarWidth *int
+	ScrollbarLeft  *bool
+	TabWidth       *int
+	WrapLineRune   *int
+	LineNumbers    *bool
+	Theme          *string
+	Keys           map[string]string // key sequence to action (ex: "ctrl+shift+r": "Reload")
+}
+
+// Directory of the config file and of the syntax rule files.
+func ConfigDir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = path.Join(os.Getenv("HOME"), ".config")
+	}
+	return path.Join(dir, "editor")
+}
+
+func DefaultConfigFilename() string {
+	return path.Join(ConfigDir(), "config.json")
+}
+
+// A missing file is an empty config.
+func ReadConfig(filename string) (*Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	cfg := &Config{}
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("config %v: %v", filename, err)
+	}
+	return cfg, nil
+}
+
+// Options with the config values, except the ones given in the command line.
+func (cfg *Config) Merge(opt *Options) *Options {
+	o := *opt
+	use := func(flagName string) bool {
+		return !opt.SetFlags[flagName]
+	}
+	if cfg.Font != nil && use("font") {
+		o.FontFilename = *cfg.Font
+	}
+	if cfg.FontSize != nil && use("fontsize") {
+		o.FontSize = *cfg.FontSize
+	}
+	if cfg.DPI != nil && use("dpi") {
+		o.DPI = *cfg.DPI
+	}
+	if cfg.ScrollbarWidth != nil && use("scrollbarwidth") {
+		o.ScrollbarWidth = *cfg.ScrollbarWidth
+	}
+	if cfg.ScrollbarLeft != nil && use("scrollbarleft") {
+		o.ScrollbarLeft = *cfg.ScrollbarLeft
+	}
+	if cfg.TabWidth != nil && use("tabwidth") {
+		o.TabWidth = *cfg.TabWidth
+	}
+	if cfg.WrapLineRune != nil && use("wraplinerune") {
+		o.WrapLineRune = *cfg.WrapLineRune
+	}
+	if cfg.LineNumbers != nil && use("linenumbers") {
+		o.LineNumbers = *cfg.LineNumbers
+	}
+	if cfg.Theme != nil && use("theme") && use("acmecolors") {
+		o.Theme = *cfg.Theme
+	}
+	o.Keys = cfg.Keys
+	return &o
+}
+
+func (ed *Editor) configOptions() (*Options, error) {
+	filename := ed.flagsOpt.ConfigFilename
+	if filename == "" {
+		filename = DefaultConfigFilename()
+	}
+	cfg, err := ReadConfig(filename)
+	if err != nil {
+		return ed.flagsOpt, err
+	}
+	return cfg.Merge(ed.flagsOpt), nil
+}
+
+// Sets the options that don't need the ui. The ui options can change only at startup.
+func (ed *Editor) setOptions(opt *Options) error {
+	ed.opt = opt
+
+	loopers.WrapLineRune = rune(opt.WrapLineRune)
+	drawutil2.TabWidth = opt.TabWidth
+	ui.ShowLineNumbers = opt.LineNumbers
+
+	theme := opt.Theme
+	if opt.AcmeColors {
+		theme = "acme"
+	}
+	if err := ui.SetTheme(theme); err != nil {
+		return err
+	}
+	return ed.setKeymap(opt.Keys)
+}
+
+// Reads the config file again. Options that need the ui to be rebuilt (font, scrollbar) are only reported.
+func (ed *Editor) ReloadConfig() {
+	opt, err := ed.configOptions()
+	if err != nil {
+		ed.Error(err)
+		return
+	}
+	old := ed.opt
+	if err := ed.setOptions(opt); err != nil {
+		ed.Error(err)
+	}
+
+	var restart []string
+	if opt.FontFilename != old.FontFilename || opt.FontSize != old.FontSize || opt.DPI != old.DPI {
+		restart = append(restart, "font")
+	}
+	if opt.ScrollbarWidth != old.ScrollbarWidth || opt.ScrollbarLeft != old.ScrollbarLeft {
+		restart = append(restart, "scrollbar")
+	}
+	if len(restart) > 0 {
+		ed.Messagef("config: restart the editor to apply the changes: %v", strings.Join(restart, ", "))
+		// keep the options in use
+		opt.FontFilename, opt.FontSize, opt.DPI = old.FontFilename, old.FontSize, old.DPI
+		opt.ScrollbarWidth, opt.ScrollbarLeft = old.ScrollbarWidth, old.ScrollbarLeft
+	}
+
+	// textareas measures depend on the tab width and wrap line rune
+	tas := []*ui.TextArea{ed.ui.Layout.Toolbar.TextArea}
+	for _, erow := range ed.erows {
+		row := erow.Row()
+		tas = append(tas, row.Toolbar.TextArea, row.TextArea)
+		row.Gutter.SetVisible(opt.LineNumbers)
+	}
+	for _, ta := range tas {
+		ta.Remeasure()
+	}
+	ed.ui.Layout.C.CalcChildsBounds()
+	ed.ui.Layout.C.NeedPaint()
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/jmigpin/editor/ui"
+	"github.com/jmigpin/editor/ui/keymap"
+	"github.com/jmigpin/editor/xgbutil/xinput"
+)
+
+func TestConfigMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := path.Join(dir, "config.json")
+	s := `{"TabWidth":4,"FontSize":14,"Theme":"acme","Keys":{"ctrl+shift+r":"Reload"}}`
+	if err := ioutil.WriteFile(filename, []byte(s), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ReadConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fontsize given in the command line
+	flagsOpt := &Options{TabWidth: 8, FontSize: 10, SetFlags: map[string]bool{"fontsize": true}}
+	opt := cfg.Merge(flagsOpt)
+	if opt.TabWidth != 4 || opt.FontSize != 10 || opt.Theme != "acme" {
+		t.Fatalf("%+v", opt)
+	}
+	if flagsOpt.TabWidth != 8 {
+		t.Fatal("flags options changed")
+	}
+
+	ed := &Editor{}
+	if err := ed.setKeymap(opt.Keys); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := xinput.ParseChord("Shift+Ctrl+R")
+	var m keymap.Matcher
+	if a, _ := m.Match(ui.TextAreaKeymap, c); a != "Reload" || c.String() != "ctrl+shift+r" {
+		t.Fatalf("%v %v", a, c)
+	}
+	// default binding
+	c, _ = xinput.ParseChord("ctrl+s")
+	if a, _ := m.Match(ui.TextAreaKeymap, c); a != "Save" {
+		t.Fatal(a)
+	}
+	if err := ed.setKeymap(map[string]string{"hyper+a": "Save"}); err == nil {
+		t.Fatal("expecting error")
+	}
+
+	// missing file
+	if _, err := ReadConfig(path.Join(dir, "nofile.json")); err != nil {
+		t.Fatal(err)
+	}
+	// unknown option
+	if err := ioutil.WriteFile(filename, []byte(`{"NoOption":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadConfig(filename); err == nil {
+		t.Fatal("expecting error")
+	}
+}
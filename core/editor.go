@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path"
+	"path/filepath"
 	"time"
 
 	"golang.org/x/image/font"
@@ -18,10 +20,11 @@ import (
 	"github.com/jmigpin/editor/core/fileswatcher"
 	"github.com/jmigpin/editor/core/toolbardata"
 	"github.com/jmigpin/editor/drawutil2"
-	"github.com/jmigpin/editor/drawutil2/loopers"
+	"github.com/jmigpin/editor/drawutil2/syntax"
 	"github.com/jmigpin/editor/ui"
 	"github.com/jmigpin/editor/xgbutil/evreg"
 	"github.com/jmigpin/editor/xgbutil/wmprotocols"
+	"github.com/jmigpin/editor/xgbutil/xinput"
 )
 
 type Editor struct {
@@ -32,6 +35,11 @@ type Editor struct {
 	homeVars  toolbardata.HomeVars
 	fwatcher  *fileswatcher.TargetWatcher
 	reopenRow *cmdutil.ReopenRow
+
+	syntaxRuleSets []*syntax.RuleSet
+
+	flagsOpt *Options // command line options
+	opt      *Options // with the config file options
 }
 
 func NewEditor(opt *Options) (*Editor, error) {
@@ -40,21 +48,20 @@ func NewEditor(opt *Options) (*Editor, error) {
 		close: make(chan struct{}),
 	}
 
-	loopers.WrapLineRune = rune(opt.WrapLineRune)
-	drawutil2.TabWidth = opt.TabWidth
+	// config errors are shown after the ui is created
+	ed.flagsOpt = opt
+	opt, cfgErr := ed.configOptions()
+	if err := ed.setOptions(opt); err != nil && cfgErr == nil {
+		cfgErr = err
+	}
 	ui.ScrollbarLeft = opt.ScrollbarLeft
-
 	ui.SetScrollbarAndSquareWidth(opt.ScrollbarWidth)
 
-	if opt.AcmeColors {
-		ui.AcmeColors()
-	}
-
 	ed.reopenRow = cmdutil.NewReopenRow(ed)
 
 	ed.homeVars.Append("~", os.Getenv("HOME"))
 
-	fface, err := ed.getFontFace(opt)
+	fface, err := ed.getFontFace(ed.opt)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +79,18 @@ func NewEditor(opt *Options) (*Editor, error) {
 			ed.Close()
 		}})
 
+	// incremental find stops when the pointer leaves the toolbar
+	incFindPointer := &evreg.Callback{func(ev0 interface{}) {
+		switch ev := ev0.(type) {
+		case *xinput.MotionNotifyEvent:
+			cmdutil.IncrementalFindPointer(ev.Point, false)
+		case *xinput.ButtonPressEvent:
+			cmdutil.IncrementalFindPointer(ev.Point, true)
+		}
+	}}
+	ed.ui.EvReg.Add(xinput.MotionNotifyEventId, incFindPointer)
+	ed.ui.EvReg.Add(xinput.ButtonPressEventId, incFindPointer)
+
 	// setup drop support (files, dirs, ...) from other applications
 	cmdutil.SetupDragNDrop(ed)
 
@@ -83,9 +102,21 @@ func NewEditor(opt *Options) (*Editor, error) {
 		&evreg.Callback{func(ev interface{}) {
 			ToolbarCmdFromLayout(ed, ed.ui.Layout)
 		}})
+	// key bindings actions on layout toolbar
+	ed.ui.Layout.Toolbar.EvReg.Add(ui.TextAreaKeyActionEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			ev := ev0.(*ui.TextAreaKeyActionEvent)
+			ed.runKeyAction(nil, ev.Action)
+		}})
 
 	cmdutil.SetupLayoutHomeVars(ed)
 
+	if cfgErr != nil {
+		ed.Error(cfgErr)
+	}
+
+	ed.readSyntaxRuleSets()
+
 	// files watcher for visual feedback when files change
 	w, err := fileswatcher.NewTargetWatcher(nil)
 	//w, err := fileswatcher.NewTargetWatcher(log.Printf)
@@ -125,6 +156,33 @@ func NewEditor(opt *Options) (*Editor, error) {
 	return ed, nil
 }
 
+// Rule sets from the syntax directory take precedence over the builtin ones.
+func (ed *Editor) readSyntaxRuleSets() {
+	dir := path.Join(ConfigDir(), "syntax")
+	u, err := syntax.ReadRuleSets(dir)
+	if err != nil {
+		ed.Error(err)
+	}
+	ed.syntaxRuleSets = append(u, syntax.BuiltinRuleSets...)
+}
+
+// Go files use the go scanner, other languages use the rule sets.
+func (ed *Editor) syntaxScanner(filename, firstLine string) (syntax.Scanner, bool) {
+	if filepath.Ext(filename) == ".go" {
+		return syntax.GoScanner{}, true
+	}
+	rs, ok := syntax.FindRuleSet(ed.syntaxRuleSets, filename, firstLine)
+	if !ok {
+		return nil, false
+	}
+	sc, err := syntax.NewRuleScanner(rs)
+	if err != nil {
+		ed.Error(err)
+		return nil, false
+	}
+	return sc, true
+}
+
 func (ed *Editor) getFontFace(opt *Options) (font.Face, error) {
 	// test font
 	// "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
@@ -186,31 +244,8 @@ func (ed *Editor) NewERowBeforeRow(tbStr string, col *ui.Column, nextRow *ui.Row
 		&evreg.Callback{func(ev0 interface{}) {
 			delete(ed.erows, row)
 		}})
-
-	// key shortcuts
-	row.EvReg.Add(ui.RowKeyPressEventId,
-		&evreg.Callback{ed.onRowKeyPress})
 	return erow
 }
-func (ed *Editor) onRowKeyPress(ev0 interface{}) {
-	ev := ev0.(*ui.RowKeyPressEvent)
-	fks := ev.Key.FirstKeysym()
-	m := ev.Key.Mods
-	switch {
-	case m.IsControl() && fks == 's':
-		erow, ok := ed.erows[ev.Row]
-		if !ok {
-			panic("!")
-		}
-		cmdutil.SaveRowFile(erow)
-	case m.IsControl() && fks == 'f':
-		erow, ok := ed.erows[ev.Row]
-		if !ok {
-			panic("!")
-		}
-		cmdutil.FindShortcut(erow)
-	}
-}
 
 func (ed *Editor) FindERow(str string) (cmdutil.ERower, bool) {
 	for _, erow := range ed.erows {
@@ -378,4 +413,10 @@ type Options struct {
 	WrapLineRune   int
 	TabWidth       int
 	ScrollbarLeft  bool
+	LineNumbers    bool
+	Theme          string
+	Keys           map[string]string // key chord to command
+
+	ConfigFilename string          // empty for the default location
+	SetFlags       map[string]bool // flags given in the command line, take precedence over the config
 }
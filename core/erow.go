@@ -9,8 +9,11 @@ import (
 
 	"github.com/jmigpin/editor/core/cmdutil"
 	"github.com/jmigpin/editor/core/contentcmd"
+	"github.com/jmigpin/editor/core/fileformat"
 	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/drawutil2/syntax"
 	"github.com/jmigpin/editor/ui"
+	"github.com/jmigpin/editor/ui/tautil"
 	"github.com/jmigpin/editor/xgbutil/evreg"
 	"github.com/pkg/errors"
 )
@@ -29,6 +32,7 @@ type ERow struct {
 		isDir    bool
 		watch    bool
 		notExist bool
+		format   fileformat.Format
 	}
 }
 
@@ -52,7 +56,34 @@ func (erow *ERow) initHandlers() {
 	row.Toolbar.EvReg.Add(ui.TextAreaSetStrEventId,
 		&evreg.Callback{func(ev0 interface{}) {
 			erow.parseToolbar()
+			cmdutil.IncrementalFindUpdate(erow)
 		}})
+	// toolbar keys
+	row.Toolbar.EvReg.Add(ui.TextAreaKeyPressEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			ev := ev0.(*ui.TextAreaKeyPressEvent)
+			ev.Handled = cmdutil.IncrementalFindKey(erow, ev.Key)
+		}})
+	// textarea keys
+	row.TextArea.EvReg.Add(ui.TextAreaKeyPressEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			ev := ev0.(*ui.TextAreaKeyPressEvent)
+			ev.Handled = cmdutil.CompletionKey(erow, ev.Key)
+		}})
+	// completion popup follows the cursor
+	completionUpdate := &evreg.Callback{func(ev0 interface{}) {
+		cmdutil.CompletionUpdate(erow)
+	}}
+	row.TextArea.EvReg.Add(ui.TextAreaSetCursorIndexEventId, completionUpdate)
+	row.TextArea.EvReg.Add(ui.TextAreaSetOffsetYEventId, completionUpdate)
+	row.TextArea.EvReg.Add(ui.TextAreaBoundsChangeEventId, completionUpdate)
+	// key bindings actions
+	keyAction := &evreg.Callback{func(ev0 interface{}) {
+		ev := ev0.(*ui.TextAreaKeyActionEvent)
+		ed.runKeyAction(erow, ev.Action)
+	}}
+	row.Toolbar.EvReg.Add(ui.TextAreaKeyActionEventId, keyAction)
+	row.TextArea.EvReg.Add(ui.TextAreaKeyActionEventId, keyAction)
 	// toolbar cmds
 	row.Toolbar.EvReg.Add(ui.TextAreaCmdEventId,
 		&evreg.Callback{func(ev0 interface{}) {
@@ -64,6 +95,7 @@ func (erow *ERow) initHandlers() {
 			if !erow.IsDir() && !erow.IsSpecialName() {
 				erow.SetUIEdited(true)
 			}
+			cmdutil.StopIncrementalFind(row)
 		}})
 	// textarea content cmds
 	row.TextArea.EvReg.Add(ui.TextAreaCmdEventId,
@@ -74,6 +106,9 @@ func (erow *ERow) initHandlers() {
 	row.EvReg.Add(ui.RowCloseEventId,
 		&evreg.Callback{func(ev0 interface{}) {
 			cmdutil.RowCtxCancel(row)
+			cmdutil.StopIncrementalFind(row)
+			cmdutil.StopCompletion(row)
+			cmdutil.ClearReplaceAll(row)
 			ed.reopenRow.Add(row)
 
 			if erow.state.watch {
@@ -175,6 +210,37 @@ func (erow *ERow) UpdateState() {
 	}
 
 	erow.SetUINotExist(cur.notExist)
+
+	if prev.filename != cur.filename || prev.isDir != cur.isDir {
+		erow.updateSyntax()
+	}
+}
+
+// Syntax highlighting and comment style by the filename, or by the shebang in the content.
+func (erow *ERow) updateSyntax() {
+	var h *syntax.Highlighter
+	var cs *tautil.CommentStyle
+	if erow.state.filename != "" && !erow.state.isDir {
+		ta := erow.row.TextArea
+		firstLine := ta.Slice(0, ta.LineEnd(0))
+		if sc, ok := erow.ed.syntaxScanner(erow.state.filename, firstLine); ok {
+			h = syntax.NewHighlighter(sc)
+		}
+		if u, ok := syntax.FindCommentStyle(syntax.CommentStyles, erow.state.filename, firstLine); ok {
+			cs = &tautil.CommentStyle{Line: u.Line, Block: u.Block}
+		}
+	}
+	erow.row.TextArea.SetSyntaxHighlighter(h)
+	erow.row.TextArea.SetCommentStyle(cs)
+}
+
+// Indentation unit detected from the content, used by the tab and return keys. Without indented lines, the unit is detected while editing.
+func (erow *ERow) updateIndentUnit() {
+	u := ""
+	if !erow.IsDir() {
+		u, _ = tautil.DetectIndent(erow.row.TextArea.Str())
+	}
+	erow.row.TextArea.SetIndentUnit(u)
 }
 
 func (erow *ERow) updateFileinfo() {
@@ -209,6 +275,11 @@ func (erow *ERow) ReloadContent() error {
 	return erow.loadContent(false)
 }
 func (erow *ERow) loadContent(clear bool) error {
+	return erow.loadContent2(clear, nil)
+}
+
+// If fe is nil, the file encoding is detected.
+func (erow *ERow) loadContent2(clear bool, fe *fileformat.Encoding) error {
 	if erow.IsSpecialName() {
 		return fmt.Errorf("can't load special name: %s", erow.state.name)
 	}
@@ -217,7 +288,20 @@ func (erow *ERow) loadContent(clear bool) error {
 	if err != nil {
 		return errors.Wrapf(err, "loadcontent")
 	}
+	var ff fileformat.Format
+	if !erow.IsDir() {
+		content, ff, err = fileformat.Decode(content, fe)
+		if err != nil {
+			return errors.Wrapf(err, "loadcontent")
+		}
+		if fileformat.MixedLineEndings(content) {
+			erow.ed.Messagef("%v: mixed line endings, kept as is", fp)
+		}
+	}
+	erow.setFileFormat(ff)
 	erow.row.TextArea.SetStrClear(content, clear, clear)
+	erow.updateSyntax() // shebang
+	erow.updateIndentUnit()
 	erow.SetUIEdited(false)
 	erow.SetUIDiskChanges(false)
 	return nil
@@ -230,7 +314,11 @@ func (erow *ERow) SaveContent(str string) error {
 	if erow.IsDir() {
 		return fmt.Errorf("can't save a directory: %v", fp)
 	}
-	err := erow.saveContent2(str, fp)
+	str, err := erow.state.format.Encode(str)
+	if err != nil {
+		return err
+	}
+	err = erow.saveContent2(str, fp)
 	if err != nil {
 		return err
 	}
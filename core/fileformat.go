@@ -0,0 +1,76 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/jmigpin/editor/core/fileformat"
+	"github.com/jmigpin/editor/core/toolbardata"
+)
+
+// The format is shown in the row status line next to the toolbar, the toolbar text is left to the user.
+func (erow *ERow) setFileFormat(ff fileformat.Format) {
+	erow.state.format = ff
+	erow.row.Status.SetInfo(ff.String())
+}
+
+// Sets the line endings used when saving the file. Mixed line endings in the content are converted.
+func lineEndingsCmd(erow *ERow, part *toolbardata.Part) {
+	a := part.Args[1:]
+	if len(a) != 1 {
+		erow.ed.Errorf("lineendings: expecting lf or crlf")
+		return
+	}
+	ff := erow.state.format
+	switch a[0].Str {
+	case "lf":
+		ff.CRLF = false
+	case "crlf":
+		ff.CRLF = true
+	default:
+		erow.ed.Errorf("lineendings: expecting lf or crlf: %v", a[0].Str)
+		return
+	}
+	ta := erow.row.TextArea
+	if s := ta.Str(); fileformat.MixedLineEndings(s) {
+		ta.SetStrClear(strings.Replace(s, "\r\n", "\n", -1), false, false)
+	}
+	if ff != erow.state.format {
+		erow.setFileFormat(ff)
+		erow.SetUIEdited(true) // needs save
+	}
+}
+
+// Sets the encoding used when saving the file, or reloads the file decoding it with the encoding.
+func encodingCmd(erow *ERow, part *toolbardata.Part) {
+	a := part.Args[1:]
+	if len(a) < 1 || len(a) > 2 || (len(a) == 2 && a[1].Str != "reload") {
+		erow.ed.Errorf("encoding: expecting <name> [reload]")
+		return
+	}
+	fe, ok := fileformat.FindEncoding(a[0].Str)
+	if !ok {
+		var names []string
+		for _, fe := range fileformat.Encodings {
+			names = append(names, fe.Name)
+		}
+		erow.ed.Errorf("encoding: unknown %q, expecting one of: %v", a[0].Str, strings.Join(names, ", "))
+		return
+	}
+	if erow.IsSpecialName() || erow.IsDir() {
+		erow.ed.Errorf("encoding: not a file")
+		return
+	}
+	if len(a) == 2 {
+		if err := erow.loadContent2(false, fe); err != nil {
+			erow.ed.Error(err)
+		}
+		return
+	}
+	ff := erow.state.format
+	ff.Enc = fe
+	ff.BOM = ff.BOM && fe.HasBOM()
+	if ff != erow.state.format {
+		erow.setFileFormat(ff)
+		erow.SetUIEdited(true) // needs save
+	}
+}
@@ -0,0 +1,187 @@
+// Encoding, line endings and byte order mark of files.
+package fileformat
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Encoding, line endings and byte order mark of a file. The content is edited as utf-8 without them, and they are restored on save.
+type Format struct {
+	Enc  *Encoding // nil is utf-8
+	CRLF bool
+	BOM  bool
+}
+
+type Encoding struct {
+	Name    string
+	aliases []string
+	bom     string
+	enc     encoding.Encoding // nil for utf-8
+}
+
+var (
+	utf8Enc    = &Encoding{Name: "utf-8", aliases: []string{"utf8"}, bom: "\xef\xbb\xbf"}
+	utf16leEnc = &Encoding{
+		Name:    "utf-16le",
+		aliases: []string{"utf16le", "utf-16"},
+		bom:     "\xff\xfe",
+		enc:     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	}
+	utf16beEnc = &Encoding{
+		Name:    "utf-16be",
+		aliases: []string{"utf16be"},
+		bom:     "\xfe\xff",
+		enc:     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	}
+	latin1Enc = &Encoding{
+		Name:    "latin-1",
+		aliases: []string{"latin1", "iso-8859-1"},
+		enc:     charmap.ISO8859_1,
+	}
+	windows1252Enc = &Encoding{
+		Name:    "windows-1252",
+		aliases: []string{"cp1252"},
+		enc:     charmap.Windows1252,
+	}
+)
+
+var Encodings = []*Encoding{utf8Enc, utf16leEnc, utf16beEnc, latin1Enc, windows1252Enc}
+
+func FindEncoding(name string) (*Encoding, bool) {
+	name = strings.ToLower(name)
+	for _, fe := range Encodings {
+		if fe.Name == name {
+			return fe, true
+		}
+		for _, a := range fe.aliases {
+			if a == name {
+				return fe, true
+			}
+		}
+	}
+	return nil, false
+}
+func (fe *Encoding) HasBOM() bool {
+	return fe.bom != ""
+}
+
+// Detects the encoding: byte order mark, utf-16 without bom (zero bytes in ascii text), or latin-1 if not valid utf-8.
+func detectEncoding(s string) (*Encoding, bool) {
+	for _, fe := range []*Encoding{utf8Enc, utf16leEnc, utf16beEnc} {
+		if strings.HasPrefix(s, fe.bom) {
+			return fe, true
+		}
+	}
+	if fe := detectUTF16(s); fe != nil {
+		return fe, false
+	}
+	if !utf8.ValidString(s) {
+		return latin1Enc, false
+	}
+	return utf8Enc, false
+}
+func detectUTF16(s string) *Encoding {
+	n := len(s)
+	if n > 2000 {
+		n = 2000
+	}
+	n -= n % 2
+	if n == 0 {
+		return nil
+	}
+	var even, odd int // zero bytes
+	for i := 0; i < n; i += 2 {
+		if s[i] == 0 {
+			even++
+		}
+		if s[i+1] == 0 {
+			odd++
+		}
+	}
+	pairs := n / 2
+	if odd*4 >= pairs && even*8 <= odd {
+		return utf16leEnc
+	}
+	if even*4 >= pairs && odd*8 <= even {
+		return utf16beEnc
+	}
+	return nil
+}
+
+// Returns the content to edit: utf-8, without the bom, and with "\n" line endings. Files with mixed line endings are kept as is (the "\r" is part of the content). If fe is nil, the encoding is detected.
+func Decode(s string, fe *Encoding) (string, Format, error) {
+	var ff Format
+	if fe == nil {
+		fe, ff.BOM = detectEncoding(s)
+	} else {
+		ff.BOM = fe.bom != "" && strings.HasPrefix(s, fe.bom)
+	}
+	ff.Enc = fe
+	if ff.BOM {
+		s = s[len(fe.bom):]
+	}
+	if fe.enc != nil {
+		u, err := fe.enc.NewDecoder().String(s)
+		if err != nil {
+			return "", ff, fmt.Errorf("decode %v: %v", fe.Name, err)
+		}
+		s = u
+	}
+	crlf := strings.Count(s, "\r\n")
+	if crlf > 0 && crlf == strings.Count(s, "\n") {
+		ff.CRLF = true
+		s = strings.Replace(s, "\r\n", "\n", -1)
+	}
+	return s, ff, nil
+}
+
+// Content with both "\n" and "\r\n" line endings.
+func MixedLineEndings(s string) bool {
+	crlf := strings.Count(s, "\r\n")
+	return crlf > 0 && crlf < strings.Count(s, "\n")
+}
+
+func (ff Format) Encode(s string) (string, error) {
+	if ff.CRLF {
+		s = strings.Replace(s, "\n", "\r\n", -1)
+	}
+	fe := ff.Encoding()
+	if fe.enc != nil {
+		u, err := fe.enc.NewEncoder().String(s)
+		if err != nil {
+			return "", fmt.Errorf("encode %v: %v", fe.Name, err)
+		}
+		s = u
+	}
+	if ff.BOM {
+		s = fe.bom + s
+	}
+	return s, nil
+}
+func (ff Format) Encoding() *Encoding {
+	if ff.Enc == nil {
+		return utf8Enc
+	}
+	return ff.Enc
+}
+
+// Shown in the row status, empty for utf-8 with "lf" and without bom.
+func (ff Format) String() string {
+	var u []string
+	if fe := ff.Encoding(); fe != utf8Enc {
+		u = append(u, fe.Name)
+	}
+	if ff.CRLF {
+		u = append(u, "crlf")
+	}
+	if ff.BOM {
+		u = append(u, "bom")
+	}
+	return strings.Join(u, " ")
+}
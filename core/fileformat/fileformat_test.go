@@ -0,0 +1,66 @@
+package fileformat
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	s0 := utf8Enc.bom + "a\r\nb\r\nc\r\n"
+	s, ff, err := Decode(s0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "a\nb\nc\n" || !ff.CRLF || !ff.BOM || ff.String() != "crlf bom" || MixedLineEndings(s) {
+		t.Fatalf("%q %+v", s, ff)
+	}
+	if u, _ := ff.Encode(s); u != s0 {
+		t.Fatalf("%q", u)
+	}
+
+	// mixed line endings are kept as is
+	for _, s0 := range []string{"a\nb\r\nc\n", "a\r\nb\r\nc\n"} {
+		s, ff, _ = Decode(s0, nil)
+		if s != s0 || ff.CRLF || ff.BOM || ff.String() != "" || !MixedLineEndings(s) {
+			t.Fatalf("%q: %q %+v", s0, s, ff)
+		}
+		if u, _ := ff.Encode(s); u != s0 {
+			t.Fatalf("%q: %q", s0, u)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		in, out, info string
+	}{
+		{"caf\xe9\n", "café\n", "latin-1"},
+		{"\xff\xfea\x00\xe9\x00\r\x00\n\x00", "aé\n", "utf-16le crlf bom"},
+		{"\x00a\x00b\x00\n", "ab\n", "utf-16be"},
+		{"a\x00b\x00\n\x00", "ab\n", "utf-16le"},
+	}
+	for _, tt := range tests {
+		s, ff, err := Decode(tt.in, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != tt.out || ff.String() != tt.info {
+			t.Fatalf("%q: %q %q", tt.in, s, ff.String())
+		}
+		u, err := ff.Encode(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u != tt.in {
+			t.Fatalf("%q: %q", tt.in, u)
+		}
+	}
+
+	// explicit encoding
+	s, ff, _ := Decode("\x93a\x94", windows1252Enc)
+	if s != "“a”" || ff.String() != "windows-1252" {
+		t.Fatalf("%q %q", s, ff.String())
+	}
+	// not representable
+	ff.Enc = latin1Enc
+	if _, err := ff.Encode(s); err == nil {
+		t.Fatal("expecting error")
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jmigpin/editor/core/cmdutil"
+	"github.com/jmigpin/editor/core/toolbardata"
+	"github.com/jmigpin/editor/ui"
+	"github.com/jmigpin/editor/ui/keymap"
+)
+
+// Editor bindings added to the textarea default bindings. Actions can also be toolbar commands.
+var defaultEditorKeyBindings = []keymap.Binding{
+	{"ctrl+s", "Save"},
+	{"ctrl+f", "FindShortcut"},
+	{"ctrl+space", "Complete"},
+}
+
+// Sets the textareas keymap with the default bindings and the config bindings. Invalid bindings are skipped and the first error is returned.
+func (ed *Editor) setKeymap(keys map[string]string) error {
+	km := ui.NewDefaultKeymap()
+	for _, b := range defaultEditorKeyBindings {
+		if err := km.Bind(b.Seq, b.Action); err != nil {
+			panic(err)
+		}
+	}
+
+	// sorted for a deterministic result on conflicting sequences
+	var seqs []string
+	for seq := range keys {
+		seqs = append(seqs, seq)
+	}
+	sort.Strings(seqs)
+	var err error
+	for _, seq := range seqs {
+		if err2 := km.Bind(seq, keys[seq]); err2 != nil && err == nil {
+			err = fmt.Errorf("config keys: %v", err2)
+		}
+	}
+
+	ui.TextAreaKeymap = km
+	return err
+}
+
+// Runs a key binding action that is not a textarea action: editor actions and toolbar commands. The erow is nil for the layout toolbar, which only runs layout commands.
+func (ed *Editor) runKeyAction(erow *ERow, action string) {
+	td := toolbardata.NewToolbarData(action, ed.HomeVars())
+	if len(td.Parts) == 0 || len(td.Parts[0].Args) == 0 {
+		return
+	}
+	part := td.Parts[0]
+	if erow == nil {
+		layoutToolbarCmd(ed, part)
+		return
+	}
+
+	switch action {
+	case "FindShortcut":
+		cmdutil.FindShortcut(erow)
+		return
+	case "Complete":
+		// completes in the row textarea
+		cmdutil.StartCompletion(erow)
+		return
+	}
+
+	if rowToolbarCmd(erow, part) || layoutToolbarCmd(ed, part) {
+		return
+	}
+	cmdutil.ExternalCmd(erow, part)
+}
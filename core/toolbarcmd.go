@@ -17,6 +17,24 @@ func ToolbarCmdFromLayout(ed *Editor, layout *ui.Layout) {
 	if !ok {
 		return
 	}
+	if layoutToolbarCmd(ed, part) {
+		return
+	}
+	// try running row command
+	erow, ok := ed.ActiveERow()
+	if ok {
+		ok := rowToolbarCmd(erow.(*ERow), part)
+		if ok {
+			return
+		}
+	}
+	// TODO: consider running external command in new row
+	err := fmt.Errorf("unknown layout command (no row is selected or it's also not a row command): %v", part.Str)
+	ed.Error(err)
+}
+
+// Returns true if cmd was handled.
+func layoutToolbarCmd(ed *Editor, part *toolbardata.Part) bool {
 	p0 := part.Args[0].Str
 	switch p0 {
 	case "Exit":
@@ -61,20 +79,17 @@ func ToolbarCmdFromLayout(ed *Editor, layout *ui.Layout) {
 
 	case "FWStatus":
 		ed.Messagef("%s", ed.fwatcher.Status())
+	case "ReloadConfig":
+		ed.ReloadConfig()
+	case "ListBindings":
+		cmdutil.ListBindings(ed)
+	case "Theme":
+		cmdutil.SetTheme(ed, part)
 
 	default:
-		// try running row command
-		erow, ok := ed.ActiveERow()
-		if ok {
-			ok := rowToolbarCmd(erow.(*ERow), part)
-			if ok {
-				return
-			}
-		}
-		// TODO: consider running external command in new row
-		err := fmt.Errorf("unknown layout command (no row is selected or it's also not a row command): %v", part.Str)
-		ed.Error(err)
+		return false
 	}
+	return true
 }
 
 func ToolbarCmdFromRow(erow *ERow) {
@@ -124,16 +139,49 @@ func rowToolbarCmd(erow *ERow, part *toolbardata.Part) bool {
 	case "CloseColumn":
 		row.Col.Cols.CloseColumnEnsureOne(row.Col)
 	case "Find":
-		a := part.Args[1:]
-		if len(a) != 1 {
-			erow.Ed().Error(fmt.Errorf("find: expecting 1 argument"))
-			break
-		}
-		tautil.Find(row.TextArea, a[0].Str)
+		cmdutil.Find(erow, part)
+	case "FindAll":
+		cmdutil.FindAll(erow, part)
+	case "Grep":
+		cmdutil.Grep(erow, part)
 	case "GotoLine":
 		cmdutil.GotoLine(erow, part)
 	case "Replace":
 		cmdutil.Replace(erow, part)
+	case "ReplaceAll":
+		cmdutil.ReplaceAll(erow, part)
+	case "Apply":
+		cmdutil.ReplaceAllApply(erow)
+	case "Undo":
+		row.TextArea.Undo()
+	case "Redo":
+		row.TextArea.Redo()
+	case "RedoBranchNext":
+		cmdutil.RedoBranch(erow, 1)
+	case "RedoBranchPrev":
+		cmdutil.RedoBranch(erow, -1)
+	case "CursorsToLines":
+		tautil.AddCursorsToLines(row.TextArea)
+	case "CursorNextMatch":
+		tautil.AddCursorNextMatch(row.TextArea)
+	case "ClearCursors":
+		tautil.ClearExtraCursors(row.TextArea)
+	case "Fold":
+		row.TextArea.ToggleFold(row.TextArea.CursorIndex())
+	case "FoldAll":
+		row.TextArea.FoldAll()
+	case "UnfoldAll":
+		row.TextArea.UnfoldAll()
+	case "LineNumbers":
+		row.Gutter.SetVisible(!row.Gutter.Visible())
+	case "IndentToTabs":
+		cmdutil.IndentToTabs(erow, part)
+	case "IndentToSpaces":
+		cmdutil.IndentToSpaces(erow, part)
+	case "LineEndings":
+		lineEndingsCmd(erow, part)
+	case "Encoding":
+		encodingCmd(erow, part)
 	case "Stop":
 		cmdutil.RowCtxCancel(row)
 	case "ListDir":
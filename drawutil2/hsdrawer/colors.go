@@ -10,6 +10,14 @@ type Colors struct {
 	Normal    FgBg
 	Selection FgBg
 	Highlight FgBg
+	Bracket   FgBg // matching brackets
+	Cursor    color.Color
+	Syntax    SyntaxColors
+}
+
+// Foreground colors of the syntax highlighting tokens.
+type SyntaxColors struct {
+	Keyword, String, Comment, Number, Builtin color.Color
 }
 
 type FgBg struct {
@@ -20,4 +28,12 @@ var DefaultColors = Colors{
 	Normal:    FgBg{color.Black, nil},
 	Selection: FgBg{color.Black, colornames.Orange},
 	Highlight: FgBg{color.Black, colornames.Aqua},
+	Bracket:   FgBg{color.Black, colornames.Lightgreen},
+	Syntax: SyntaxColors{
+		Keyword: colornames.Navy,
+		String:  colornames.Darkgreen,
+		Comment: colornames.Gray,
+		Number:  colornames.Darkmagenta,
+		Builtin: colornames.Teal,
+	},
 }
@@ -2,9 +2,11 @@ package hsdrawer
 
 import (
 	"image"
+	"image/color"
 	"image/draw"
 
 	"github.com/jmigpin/editor/drawutil2/loopers"
+	"github.com/jmigpin/editor/drawutil2/syntax"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
@@ -13,7 +15,7 @@ import (
 // Highlight and Selection drawer.
 type HSDrawer struct {
 	Face font.Face
-	Str  string
+	Text loopers.Text
 
 	Colors      *Colors
 	CursorIndex int // <0 to disable
@@ -21,11 +23,27 @@ type HSDrawer struct {
 	Selection   *loopers.SelectionIndexes
 	OffsetY     fixed.Int26_6
 
+	// multiple cursors (sorted)
+	ExtraCursors    []int
+	ExtraSelections []*loopers.SelectionIndexes
+
+	SelectionBlock *fixed.Rectangle26_6 // rectangular selection
+
+	HighlightRanges  []*loopers.SelectionIndexes // sorted
+	MatchingBrackets []*loopers.SelectionIndexes // sorted
+
+	Syntax *syntax.Highlighter // kept updated externally
+
+	// sorted ranges of whole lines, shown as a placeholder line (needs a measure)
+	Folds []*loopers.SelectionIndexes
+
 	height fixed.Int26_6
+	max    image.Point
 
 	pdl    *loopers.PosDataLooper
 	pdk    *HSPosDataKeeper
 	wlinel *loopers.WrapLineLooper
+	foldl  *loopers.FoldLooper
 }
 
 func NewHSDrawer(face font.Face) *HSDrawer {
@@ -41,7 +59,8 @@ func NewHSDrawer(face font.Face) *HSDrawer {
 func (d *HSDrawer) Measure(max *image.Point) *fixed.Point26_6 {
 	max2 := fixed.P(max.X, max.Y)
 
-	strl := loopers.NewStringLooper(d.Face, d.Str)
+	strl := loopers.NewTextLooper(d.Face, d.Text)
+	foldl := loopers.NewFoldLooper(strl)
 	linel := loopers.NewLineLooper(strl, max2.Y)
 	wlinel := loopers.NewWrapLineLooper(strl, linel, max2.X)
 	d.pdk = NewHSPosDataKeeper(wlinel)
@@ -49,9 +68,12 @@ func (d *HSDrawer) Measure(max *image.Point) *fixed.Point26_6 {
 	ml := loopers.NewMeasureLooper(strl, &max2)
 
 	d.wlinel = wlinel
+	d.foldl = foldl
+	foldl.Folds = d.Folds
 
 	// iterator order
-	linel.SetOuterLooper(strl)
+	foldl.SetOuterLooper(strl)
+	linel.SetOuterLooper(foldl)
 	wlinel.SetOuterLooper(linel)
 	d.pdl.SetOuterLooper(wlinel)
 	ml.SetOuterLooper(d.pdl)
@@ -59,12 +81,48 @@ func (d *HSDrawer) Measure(max *image.Point) *fixed.Point26_6 {
 	ml.Loop(func() bool { return true })
 
 	d.height = ml.M.Y
-	if d.Str == "" {
+	d.max = *max
+	if d.Text.Len() == 0 {
 		d.height = 0
 	}
 
 	return ml.M
 }
+
+// Measures only the lines affected by a change (see Measure). The string was changed at [index,index+oldN) to [index,index+newN).
+func (d *HSDrawer) MeasureChange(index, oldN, newN int) {
+	if d.pdl == nil || !d.pdl.HasData() {
+		max := d.max
+		d.Measure(&max)
+		return
+	}
+
+	d.pdl.Strl.Text = d.Text
+	d.foldl.Folds = d.Folds
+
+	// restart at the line start of the change
+	restart := d.Text.LineStart(index)
+
+	// layout converges at the line start after the change
+	converge := -1
+	if i := d.Text.LineEnd(index + newN); i < d.Text.Len() {
+		converge = i + 1
+	}
+
+	fn := func() bool { return true }
+	dy, ok := d.pdl.LoopChange(fn, restart, converge, newN-oldN)
+	if ok {
+		d.height += dy
+	} else {
+		d.height = d.pdl.Strl.PenBounds().Max.Y
+		if maxY := fixed.I(d.max.Y); d.height > maxY {
+			d.height = maxY
+		}
+	}
+	if d.Text.Len() == 0 {
+		d.height = 0
+	}
+}
 func (d *HSDrawer) Draw(img draw.Image, bounds *image.Rectangle) {
 	strl := d.pdl.Strl
 	wlinel := d.wlinel
@@ -73,25 +131,49 @@ func (d *HSDrawer) Draw(img draw.Image, bounds *image.Rectangle) {
 	sl := loopers.NewSelectionLooper(strl, bgl, dl)
 	cursorl := loopers.NewCursorLooper(strl, dl)
 	hwl := loopers.NewHWordLooper(strl, bgl, dl, sl)
+	hrl := loopers.NewHRangesLooper(strl, bgl, dl)
+	hbl := loopers.NewHRangesLooper(strl, bgl, dl)
 	scl := loopers.NewSetColorsLooper(dl, bgl)
+	synl := loopers.NewSyntaxLooper(strl, dl)
 	eel := loopers.NewEarlyExitLooper(strl, bounds)
 
 	// options
 	scl.Fg = d.Colors.Normal.Fg
 	scl.Bg = nil // d.Colors.Normal.Bg // default bg filled externallly
 	sl.Selection = d.Selection
+	sl.Extra = d.ExtraSelections
+	if d.SelectionBlock != nil {
+		// pen is moved by the offset
+		b := *d.SelectionBlock
+		b.Min.Y -= d.OffsetY
+		b.Max.Y -= d.OffsetY
+		sl.Block = &b
+	}
 	sl.Fg = d.Colors.Selection.Fg
 	sl.Bg = d.Colors.Selection.Bg
 	hwl.WordIndex = d.HWordIndex
 	hwl.Fg = d.Colors.Highlight.Fg
 	hwl.Bg = d.Colors.Highlight.Bg
+	hrl.Ranges = d.HighlightRanges
+	hrl.Fg = d.Colors.Highlight.Fg
+	hrl.Bg = d.Colors.Highlight.Bg
+	hbl.Ranges = d.MatchingBrackets
+	hbl.Fg = d.Colors.Bracket.Fg
+	hbl.Bg = d.Colors.Bracket.Bg
 	cursorl.CursorIndex = d.CursorIndex
+	cursorl.Color = d.Colors.Cursor
+	cursorl.Extra = d.ExtraCursors
+	if d.Syntax != nil {
+		synl.Colorize = d.syntaxColor
+	}
 
 	// draw background first to correctly paint letters above the background
 
 	// bg iteration order
 	scl.SetOuterLooper(wlinel)
-	sl.SetOuterLooper(scl)
+	hrl.SetOuterLooper(scl)
+	hbl.SetOuterLooper(hrl)
+	sl.SetOuterLooper(hbl)
 	hwl.SetOuterLooper(sl)
 	bgl.SetOuterLooper(hwl)
 	eel.SetOuterLooper(bgl)
@@ -104,8 +186,11 @@ func (d *HSDrawer) Draw(img draw.Image, bounds *image.Rectangle) {
 	// draw bg
 	eel.Loop(func() bool { return true })
 
-	// iterator order
-	cursorl.SetOuterLooper(wlinel)
+	// iterator order (sets the fg colors again, the bg iteration leaves the last rune colors)
+	scl.SetOuterLooper(wlinel)
+	synl.SetOuterLooper(scl)
+	sl.SetOuterLooper(synl)
+	cursorl.SetOuterLooper(sl)
 	dl.SetOuterLooper(cursorl)
 	eel.SetOuterLooper(dl)
 
@@ -117,6 +202,23 @@ func (d *HSDrawer) Draw(img draw.Image, bounds *image.Rectangle) {
 	eel.Loop(func() bool { return true })
 }
 
+func (d *HSDrawer) syntaxColor(ri int) color.Color {
+	c := &d.Colors.Syntax
+	switch d.Syntax.KindAt(ri) {
+	case syntax.Keyword:
+		return c.Keyword
+	case syntax.String:
+		return c.String
+	case syntax.Comment:
+		return c.Comment
+	case syntax.Number:
+		return c.Number
+	case syntax.Builtin:
+		return c.Builtin
+	}
+	return nil
+}
+
 func (d *HSDrawer) Height() fixed.Int26_6 {
 	return d.height
 }
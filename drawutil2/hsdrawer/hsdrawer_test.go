@@ -2,14 +2,112 @@ package hsdrawer
 
 import (
 	"image"
+	"strings"
 	"testing"
 
 	"github.com/jmigpin/editor/drawutil2"
 	"github.com/jmigpin/editor/drawutil2/loopers"
+	"golang.org/x/image/math/fixed"
 )
 
 var loremStr = `Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim id est laborum.`
 
+func testMeasureChange(t *testing.T, str string, index, oldN int, istr string) {
+	f1 := drawutil2.GetTestFace()
+	face := drawutil2.NewFaceCache(drawutil2.NewFaceRunes(f1))
+	max := image.Point{300, 100000}
+
+	d := &HSDrawer{Face: face, Text: loopers.StrText(str)}
+	d.Measure(&max)
+
+	str2 := str[:index] + istr + str[index+oldN:]
+	d.Text = loopers.StrText(str2)
+	d.MeasureChange(index, oldN, len(istr))
+
+	d2 := &HSDrawer{Face: face, Text: loopers.StrText(str2)}
+	d2.Measure(&max)
+
+	if d.Height() != d2.Height() {
+		t.Fatalf("height %v != %v", d.Height(), d2.Height())
+	}
+	for i := 0; i <= len(str2); i += 7 {
+		p1, p2 := d.GetPoint(i), d2.GetPoint(i)
+		if *p1 != *p2 {
+			t.Fatalf("index %v: point %v != %v", i, p1, p2)
+		}
+		i1, i2 := d.GetIndex(p2), d2.GetIndex(p2)
+		if i1 != i2 {
+			t.Fatalf("point %v: index %v != %v", p2, i1, i2)
+		}
+	}
+}
+
+func TestMeasureChange1(t *testing.T) {
+	str := strings.Repeat(loremStr+"\n", 20)
+	testMeasureChange(t, str, 10, 0, "abc")                           // insert
+	testMeasureChange(t, str, 10, 100, "")                            // delete
+	testMeasureChange(t, str, 5000, 3, "\n\n\n")                      // add lines
+	testMeasureChange(t, str, 5000, 1000, loremStr+loremStr+loremStr) // wrap more
+	testMeasureChange(t, str, len(str), 0, "end")                     // append
+}
+func TestMeasureChange2(t *testing.T) {
+	str := strings.Repeat("a\n\tbcd\n", 3000)
+	testMeasureChange(t, str, 0, 0, "0")
+	testMeasureChange(t, str, len(str)/2, 5, "")
+	testMeasureChange(t, str, len(str)-1, 1, "")
+	testMeasureChange(t, str, 0, len(str), "")
+	testMeasureChange(t, "", 0, 0, str)
+}
+
+func TestFolds(t *testing.T) {
+	f1 := drawutil2.GetTestFace()
+	face := drawutil2.NewFaceCache(drawutil2.NewFaceRunes(f1))
+	max := image.Point{300, 100000}
+
+	str := "func f() {\n\ta\n\tb\n\tc\n}\nend"
+	fold := &loopers.SelectionIndexes{Start: 11, End: 20} // body lines
+	d := &HSDrawer{Face: face, Text: loopers.StrText(str)}
+	d.Folds = []*loopers.SelectionIndexes{fold}
+	d.Measure(&max)
+
+	d2 := &HSDrawer{Face: face, Text: loopers.StrText(str)}
+	d2.Measure(&max)
+
+	// the 3 lines are shown as one placeholder line
+	lh := d.LineHeight()
+	if d.Height() != d2.Height()-2*lh {
+		t.Fatalf("height %v, %v", d.Height(), d2.Height())
+	}
+	if p := d.GetPoint(fold.End); p.Y != 2*lh || p.X != 0 {
+		t.Fatalf("point %v", p)
+	}
+	// the placeholder line has the fold start index
+	if i := d.GetIndex(&fixed.Point26_6{fixed.I(5), lh + lh/2}); i != fold.Start {
+		t.Fatalf("index %v", i)
+	}
+	if i := d.GetIndex(&fixed.Point26_6{0, 2*lh + lh/2}); i != fold.End {
+		t.Fatalf("index %v", i)
+	}
+}
+
+func BenchmarkMeasureChange(b *testing.B) {
+	f1 := drawutil2.GetTestFace()
+	face := drawutil2.NewFaceCache(drawutil2.NewFaceRunes(f1))
+	max := image.Point{300, 10000000}
+
+	str := strings.Repeat("a\n\tbcd\n", 50000)
+	d := &HSDrawer{Face: face, Text: loopers.StrText(str)}
+	d.Measure(&max)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// typing at the end
+		str += "a"
+		d.Text = loopers.StrText(str)
+		d.MeasureChange(len(str)-1, 0, 1)
+	}
+}
+
 func BenchmarkDraw(b *testing.B) {
 	f1 := drawutil2.GetTestFace()
 	f2 := drawutil2.NewFaceRunes(f1)
@@ -24,7 +122,7 @@ func BenchmarkDraw(b *testing.B) {
 		str += loremStr
 	}
 
-	d := &HSDrawer{Face: face, Str: str}
+	d := &HSDrawer{Face: face, Text: loopers.StrText(str)}
 	d.CursorIndex = 3
 	d.HWordIndex = 15
 	d.Selection = &loopers.SelectionIndexes{4, 50}
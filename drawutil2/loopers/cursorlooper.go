@@ -2,6 +2,7 @@ package loopers
 
 import (
 	"image/color"
+	"sort"
 
 	"github.com/jmigpin/editor/imageutil"
 )
@@ -11,28 +12,42 @@ type CursorLooper struct {
 	strl        *StringLooper
 	dl          *DrawLooper
 	CursorIndex int
+	Extra       []int       // extra cursors indexes, sorted
+	Color       color.Color // defaults to black
 }
 
 func NewCursorLooper(strl *StringLooper, dl *DrawLooper) *CursorLooper {
 	return &CursorLooper{strl: strl, dl: dl}
 }
 func (lpr *CursorLooper) Loop(fn func() bool) {
-	ci := lpr.CursorIndex
 	lpr.OuterLooper().Loop(func() bool {
-		if !lpr.strl.RiClone && lpr.strl.Ri == ci {
+		if !lpr.strl.RiClone && lpr.isCursor(lpr.strl.Ri) {
 			lpr.drawCursor()
 		}
 		return fn()
 	})
 	// draw past last position if at str len
-	if !lpr.strl.RiClone && lpr.strl.Ri == ci && ci == len(lpr.strl.Str) {
+	n := lpr.strl.Text.Len()
+	if !lpr.strl.RiClone && lpr.strl.Ri == n && lpr.isCursor(n) {
 		lpr.drawCursor()
 	}
 }
+func (lpr *CursorLooper) isCursor(i int) bool {
+	if i == lpr.CursorIndex {
+		return true
+	}
+	k := sort.SearchInts(lpr.Extra, i)
+	return k < len(lpr.Extra) && lpr.Extra[k] == i
+}
 func (lpr *CursorLooper) drawCursor() {
 	img := lpr.dl.Image
 	bounds := lpr.dl.Bounds
 
+	var c color.Color = color.Black
+	if lpr.Color != nil {
+		c = lpr.Color
+	}
+
 	pb := lpr.strl.PenBoundsForImage()
 	dr := pb.Add(bounds.Min)
 
@@ -42,7 +57,7 @@ func (lpr *CursorLooper) drawCursor() {
 	r1.Max.X = r1.Min.X + 3
 	r1.Max.Y = r1.Min.Y + 3
 	r1 = r1.Intersect(*bounds)
-	imageutil.FillRectangle(img, &r1, &color.Black)
+	imageutil.FillRectangle(img, &r1, c)
 
 	// lower square
 	r2 := dr
@@ -50,11 +65,11 @@ func (lpr *CursorLooper) drawCursor() {
 	r2.Max.X = r2.Min.X + 3
 	r2.Min.Y = r2.Max.Y - 3
 	r2 = r2.Intersect(*bounds)
-	imageutil.FillRectangle(img, &r2, &color.Black)
+	imageutil.FillRectangle(img, &r2, c)
 
 	// vertical bar
 	r3 := dr
 	r3.Max.X = r3.Min.X + 1
 	r3 = r3.Intersect(*bounds)
-	imageutil.FillRectangle(img, &r3, &color.Black)
+	imageutil.FillRectangle(img, &r3, c)
 }
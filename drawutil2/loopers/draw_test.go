@@ -43,3 +43,26 @@ func BenchmarkDraw2(b *testing.B) {
 		dl.Loop(func() bool { return true })
 	}
 }
+
+func TestHRangesLooper(t *testing.T) {
+	face := drawutil2.GetTestFace()
+	img := image.NewRGBA(drawRect)
+	bounds := img.Bounds()
+	strl := NewStringLooper(face, "abcdef")
+	dl := NewDrawLooper(strl, img, &bounds)
+	bgl := NewBgLooper(strl, dl)
+	hrl := NewHRangesLooper(strl, bgl, dl)
+	hrl.Ranges = []*SelectionIndexes{{1, 3}, {5, 4}}
+	hrl.SetOuterLooper(strl)
+
+	u := ""
+	hrl.Loop(func() bool {
+		if hrl.colorize() {
+			u += string(strl.Ru)
+		}
+		return true
+	})
+	if u != "bce" {
+		t.Fatal(u)
+	}
+}
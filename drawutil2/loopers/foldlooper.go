@@ -0,0 +1,87 @@
+package loopers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Replaces each fold with a placeholder line. Folds are ranges of whole lines, the placeholder runes are clones with the index of the fold start.
+type FoldLooper struct {
+	EmbedLooper
+	strl  *StringLooper
+	Folds []*SelectionIndexes // sorted and not overlapping
+
+	placeholders map[SelectionIndexes]string // the folded text is only read once
+}
+
+func NewFoldLooper(strl *StringLooper) *FoldLooper {
+	return &FoldLooper{strl: strl}
+}
+func (lpr *FoldLooper) Loop(fn func() bool) {
+	if len(lpr.Folds) == 0 {
+		lpr.OuterLooper().Loop(fn)
+		return
+	}
+	strl := lpr.strl
+	lpr.OuterLooper().Loop(func() bool {
+		if strl.RiClone {
+			return fn()
+		}
+		f, ok := lpr.foldAt(strl.Ri)
+		if !ok {
+			return fn()
+		}
+
+		// placeholder
+		strl.RiClone = true
+		for _, ru := range lpr.placeholder(f) {
+			strl.Ru = ru
+			if !strl.Iterate(fn) {
+				return false
+			}
+		}
+		strl.RiClone = false
+
+		// continue after the fold
+		strl.Ri = f.End
+		if strl.Ri >= strl.Text.Len() {
+			return true
+		}
+		strl.Ru, _ = strl.Text.ReadRuneAt(strl.Ri)
+		strl.AddKern()
+		strl.CalcAdvance()
+		return fn()
+	})
+}
+func (lpr *FoldLooper) foldAt(ri int) (*SelectionIndexes, bool) {
+	k := sort.Search(len(lpr.Folds), func(i int) bool {
+		return lpr.Folds[i].Start >= ri
+	})
+	if k < len(lpr.Folds) && lpr.Folds[k].Start == ri {
+		return lpr.Folds[k], true
+	}
+	return nil, false
+}
+
+// Keeps the indentation of the first folded line.
+func (lpr *FoldLooper) placeholder(f *SelectionIndexes) string {
+	if p, ok := lpr.placeholders[*f]; ok {
+		return p
+	}
+	if lpr.placeholders == nil || len(lpr.placeholders) > 2*len(lpr.Folds) {
+		lpr.placeholders = map[SelectionIndexes]string{}
+	}
+	s := lpr.strl.Text.Slice(f.Start, f.End)
+	indent := s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+	n := strings.Count(s, "\n")
+	nl := ""
+	if strings.HasSuffix(s, "\n") {
+		nl = "\n"
+	} else {
+		n++
+	}
+	p := fmt.Sprintf("%s… %d lines%s", indent, n, nl)
+	lpr.placeholders[*f] = p
+	return p
+}
@@ -0,0 +1,47 @@
+package loopers
+
+import (
+	"image/color"
+	"sort"
+)
+
+// Highlights multiple ranges (ex: all the matches of a search).
+type HRangesLooper struct {
+	EmbedLooper
+	strl *StringLooper
+	bgl  *BgLooper
+	dl   *DrawLooper
+
+	Ranges []*SelectionIndexes // sorted and not overlapping
+	Fg, Bg color.Color
+}
+
+func NewHRangesLooper(strl *StringLooper, bgl *BgLooper, dl *DrawLooper) *HRangesLooper {
+	return &HRangesLooper{strl: strl, bgl: bgl, dl: dl}
+}
+func (lpr *HRangesLooper) Loop(fn func() bool) {
+	if len(lpr.Ranges) == 0 {
+		lpr.OuterLooper().Loop(fn)
+		return
+	}
+	lpr.OuterLooper().Loop(func() bool {
+		if lpr.colorize() {
+			if lpr.Fg != nil {
+				lpr.dl.Fg = lpr.Fg
+			}
+			lpr.bgl.Bg = lpr.Bg
+		}
+		return fn()
+	})
+}
+func (lpr *HRangesLooper) colorize() bool {
+	if lpr.strl.RiClone {
+		return false
+	}
+	ri := lpr.strl.Ri
+	k := sort.Search(len(lpr.Ranges), func(i int) bool {
+		_, e := lpr.Ranges[i].sorted()
+		return e > ri
+	})
+	return k < len(lpr.Ranges) && lpr.Ranges[k].in(ri)
+}
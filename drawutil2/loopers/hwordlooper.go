@@ -39,7 +39,7 @@ func NewHWordLooper(
 }
 func (lpr *HWordLooper) Loop(fn func() bool) {
 	if lpr.WordIndex >= 0 {
-		word, _, ok := wordAtIndex(lpr.strl.Str, lpr.WordIndex)
+		word, _, ok := wordAtIndex(lpr.strl.Text, lpr.WordIndex)
 		lpr.hword.on = ok
 		lpr.hword.word = word
 	}
@@ -55,7 +55,7 @@ func (lpr *HWordLooper) Loop(fn func() bool) {
 }
 func (lpr *HWordLooper) colorize() bool {
 	// don't highlight words if a selection is on
-	if lpr.sl.Selection != nil {
+	if lpr.sl.On() {
 		return false
 	}
 
@@ -70,7 +70,7 @@ func (lpr *HWordLooper) colorize() bool {
 		inWord = true
 	}
 	if !inWord {
-		stopIndex, ok := matchWordAtIndex(lpr.hword.word, lpr.strl.Str, lpr.strl.Ri)
+		stopIndex, ok := matchWordAtIndex(lpr.hword.word, lpr.strl.Text, lpr.strl.Ri)
 		if ok {
 			lpr.hword.start = lpr.strl.Ri
 			lpr.hword.end = stopIndex
@@ -80,8 +80,8 @@ func (lpr *HWordLooper) colorize() bool {
 	return inWord
 }
 
-func wordAtIndex(str string, index int) (string, int, bool) {
-	if index > len(str) {
+func wordAtIndex(text Text, index int) (string, int, bool) {
+	if index > text.Len() {
 		return "", 0, false
 	}
 
@@ -90,10 +90,10 @@ func wordAtIndex(str string, index int) (string, int, bool) {
 
 	// right limit
 	max := index + cap
-	if max > len(str) {
-		max = len(str)
+	if max > text.Len() {
+		max = text.Len()
 	}
-	str2 := str[index:max]
+	str2 := text.Slice(index, max)
 	str3 := str2 + " " // allow to find on eos
 	i := strings.IndexFunc(str3, isNotWordRune)
 	if i <= 0 {
@@ -107,7 +107,7 @@ func wordAtIndex(str string, index int) (string, int, bool) {
 	if min < 0 {
 		min = 0
 	}
-	str2 = str[min:index]
+	str2 = text.Slice(min, index)
 	li := strings.LastIndexFunc(str2, isNotWordRune)
 	if li < 0 {
 		li = 0
@@ -118,24 +118,29 @@ func wordAtIndex(str string, index int) (string, int, bool) {
 	}
 	li += min
 
-	return str[li:ri], li, true
+	return text.Slice(li, ri), li, true
 }
 
-func matchWordAtIndex(word string, str string, index int) (stopIndex int, ok bool) {
+func matchWordAtIndex(word string, text Text, index int) (stopIndex int, ok bool) {
+	// first rune must match (avoids slicing the text at each rune)
+	ru, slze := text.ReadRuneAt(index)
+	if w, _ := utf8.DecodeRuneInString(word); slze == 0 || ru != w {
+		return 0, false
+	}
 	// previous rune can't be a word rune
-	ru, slze := utf8.DecodeLastRuneInString(str[:index])
+	ru, slze = text.ReadLastRuneAt(index)
 	if slze != 0 && isWordRune(ru) {
 		return 0, false
 	}
 	e := index + len(word)
-	if e <= len(str) {
+	if e <= text.Len() {
 		// next rune can't be a word rune
-		ru, slze = utf8.DecodeRuneInString(str[e:])
+		ru, slze = text.ReadRuneAt(e)
 		if slze != 0 && isWordRune(ru) {
 			return 0, false
 		}
 		// match words
-		if word == str[index:e] {
+		if word == text.Slice(index, e) {
 			return e, true
 		}
 	}
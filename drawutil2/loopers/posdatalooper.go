@@ -22,23 +22,87 @@ func (pdl *PosDataLooper) Loop(fn func() bool) {
 	pdl.data = []*PosData{}
 
 	// keep values of first iteration, if string empty it's ok to not keep anything
-	count := 0
+	count, next := 0, 0
 	pdl.OuterLooper().Loop(func() bool {
-		if count%jump == 0 {
-			pdl.keep()
+		if count >= next && pdl.keep() {
+			next = count + jump
 		}
 		count++
 		return fn()
 	})
 }
 
-func (pdl *PosDataLooper) keep() {
+// Loops from the data kept before restartIndex. Stops at the old data past convergeIndex (shifted by delta) since from there the layout is the same, only shifted vertically.
+// Returns the vertical shift and true if the old data was reused.
+func (pdl *PosDataLooper) LoopChange(fn func() bool, restartIndex, convergeIndex, delta int) (fixed.Int26_6, bool) {
+	jump := 250 // experimental value
+
+	// last data before the restart index
+	n := sort.Search(len(pdl.data), func(i int) bool {
+		return pdl.data[i].ri >= restartIndex
+	})
+	if n > 0 {
+		n--
+	}
+	pdl.restore(pdl.data[n])
+
+	// split data, the first entry is kept again on the first iteration
+	old := append([]*PosData{}, pdl.data[n+1:]...)
+	pdl.data = pdl.data[:n]
+
+	// first old data past the converge index
+	k := sort.Search(len(old), func(i int) bool {
+		return convergeIndex >= 0 && old[i].ri+delta >= convergeIndex
+	})
+
+	converged := false
+	count, next := 0, 0
+	pdl.OuterLooper().Loop(func() bool {
+		if k < len(old) && pdl.Strl.Ri == old[k].ri+delta && !pdl.Strl.RiClone {
+			converged = true
+			return false
+		}
+		if count >= next && pdl.keep() {
+			next = count + jump
+		}
+		count++
+		return fn()
+	})
+	if !converged {
+		return 0, false
+	}
+
+	// reuse old data shifted
+	dy := pdl.Strl.Pen.Y - old[k].pen.Y
+	for _, pd := range old[k:] {
+		pd.ri += delta
+		pd.pen.Y += dy
+		pd.penBoundsMaxY += dy
+		pdl.data = append(pdl.data, pd)
+	}
+	return dy, true
+}
+
+func (pdl *PosDataLooper) HasData() bool {
+	return len(pdl.data) > 0
+}
+
+func (pdl *PosDataLooper) keep() bool {
+	// a restored clone rune (ex: wraplinerune) would be replaced by the original rune at the clone position
+	if pdl.Strl.RiClone {
+		return false
+	}
+
+	// pen before the kern, it's added again when iterating after a restore
+	pen := pdl.Strl.Pen
+	pen.X -= pdl.Strl.Face.Kern(pdl.Strl.PrevRu, pdl.Strl.Ru)
+
 	data := pdl.pdk.KeepPosData()
 	pd := &PosData{
 		ri:      pdl.Strl.Ri,
 		riClone: pdl.Strl.RiClone,
 		prevRu:  pdl.Strl.PrevRu,
-		pen:     pdl.Strl.Pen,
+		pen:     pen,
 
 		// not to be restored, just used for detection
 		penBoundsMaxY: pdl.Strl.PenBounds().Max.Y,
@@ -46,6 +110,7 @@ func (pdl *PosDataLooper) keep() {
 		data: data,
 	}
 	pdl.data = append(pdl.data, pd)
+	return true
 }
 func (pdl *PosDataLooper) restore(pd *PosData) {
 	pdl.Strl.Ri = pd.ri
@@ -102,7 +167,8 @@ func (pdl *PosDataLooper) PosDataCloseToPoint(p *fixed.Point26_6) (*PosData, boo
 func (pdl *PosDataLooper) GetPoint(index int, looper Looper) *fixed.Point26_6 {
 	strl := pdl.Strl
 	looper.Loop(func() bool {
-		if strl.Ri >= index {
+		// clone runes (ex: wraplinerune) share the index of the next rune
+		if strl.Ri >= index && !strl.RiClone {
 			return false
 		}
 		return true
@@ -151,13 +217,13 @@ func (pdl *PosDataLooper) GetIndex(p *fixed.Point26_6, looper Looper) int {
 	}
 	if foundLine {
 		// position at end of string if last line and not a newline
-		if strl.Ri == len(strl.Str) && strl.PrevRu != '\n' {
-			return len(strl.Str)
+		if strl.Ri == strl.Text.Len() && strl.PrevRu != '\n' {
+			return strl.Ri
 		}
 
 		return lineRuneIndex
 	}
-	return len(strl.Str)
+	return strl.Text.Len()
 }
 
 type PosDataKeeper interface {
@@ -1,6 +1,11 @@
 package loopers
 
-import "image/color"
+import (
+	"image/color"
+	"sort"
+
+	"golang.org/x/image/math/fixed"
+)
 
 type SelectionLooper struct {
 	EmbedLooper
@@ -8,6 +13,8 @@ type SelectionLooper struct {
 	bgl       *BgLooper
 	dl        *DrawLooper
 	Selection *SelectionIndexes
+	Extra     []*SelectionIndexes  // extra selections, sorted and not overlapping
+	Block     *fixed.Rectangle26_6 // rectangular selection, in pen coordinates
 	Fg, Bg    color.Color
 }
 
@@ -26,20 +33,51 @@ func (lpr *SelectionLooper) Loop(fn func() bool) {
 	})
 }
 func (lpr *SelectionLooper) colorize() bool {
-	sl := lpr.Selection
-	if sl == nil {
-		return false
-	}
 	if lpr.strl.RiClone {
 		return false
 	}
-	s, e := sl.Start, sl.End
-	if s > e {
-		s, e = e, s
+	if lpr.Block != nil {
+		return lpr.inBlock()
+	}
+	ri := lpr.strl.Ri
+	if lpr.Selection != nil && lpr.Selection.in(ri) {
+		return true
 	}
-	return lpr.strl.Ri >= s && lpr.strl.Ri < e
+	// first selection ending after ri
+	k := sort.Search(len(lpr.Extra), func(i int) bool {
+		_, e := lpr.Extra[i].sorted()
+		return e > ri
+	})
+	return k < len(lpr.Extra) && lpr.Extra[k].in(ri)
+}
+
+// Same column math as the line indexes of the block: a rune is inside if it ends after the block start, and doesn't contain the block end (see PosDataLooper.GetIndex).
+func (lpr *SelectionLooper) inBlock() bool {
+	if lpr.strl.Ru == '\n' {
+		return false
+	}
+	b := lpr.Block
+	pb := lpr.strl.PenBounds()
+	return pb.Min.Y >= b.Min.Y && pb.Max.Y <= b.Max.Y &&
+		pb.Max.X > b.Min.X && pb.Max.X <= b.Max.X
+}
+
+// Returns true if there is some selection to be drawn.
+func (lpr *SelectionLooper) On() bool {
+	return lpr.Selection != nil || len(lpr.Extra) > 0 || lpr.Block != nil
 }
 
 type SelectionIndexes struct {
 	Start, End int
 }
+
+func (si *SelectionIndexes) sorted() (int, int) {
+	if si.Start > si.End {
+		return si.End, si.Start
+	}
+	return si.Start, si.End
+}
+func (si *SelectionIndexes) in(i int) bool {
+	s, e := si.sorted()
+	return i >= s && i < e
+}
@@ -14,7 +14,7 @@ type StringLooper struct {
 	EmbedLooper // not used, this is the outmost looper
 
 	Face    font.Face
-	Str     string
+	Text    Text
 	Ri      int
 	Ru      rune
 	PrevRu  rune
@@ -27,26 +27,35 @@ type StringLooper struct {
 }
 
 func NewStringLooper(face font.Face, str string) *StringLooper {
+	return NewTextLooper(face, StrText(str))
+}
+func NewTextLooper(face font.Face, text Text) *StringLooper {
 	fm := face.Metrics()
 	lpr := &StringLooper{
 		Face:    face,
 		Metrics: &fm,
-		Str:     str,
+		Text:    text,
 	}
 	lpr.Pen.Y = lpr.LineBaseline()
 	return lpr
 }
 func (lpr *StringLooper) Loop(fn func() bool) {
-	o := lpr.Ri
-	for Ri, Ru := range lpr.Str[o:] {
-		lpr.Ri = o + Ri
-		lpr.Ru = Ru
+	n := lpr.Text.Len()
+	for lpr.Ri < n {
+		ri := lpr.Ri
+		ru, size := lpr.Text.ReadRuneAt(ri)
+		lpr.Ru = ru
 		if !lpr.Iterate(fn) {
 			return
 		}
+		// inner loopers can move the index (ex: folds)
+		if lpr.Ri != ri {
+			_, size = lpr.Text.ReadRuneAt(lpr.Ri)
+		}
+		lpr.Ri += size
 	}
 	// set ri to allow testing that it reached the end
-	lpr.Ri = len(lpr.Str)
+	lpr.Ri = n
 }
 func (lpr *StringLooper) Iterate(fn func() bool) bool {
 	lpr.AddKern()
@@ -0,0 +1,30 @@
+package loopers
+
+import "image/color"
+
+// Sets the foreground color of each rune given by the colorize function (ex: syntax highlighting). A nil color keeps the previous color.
+type SyntaxLooper struct {
+	EmbedLooper
+	strl *StringLooper
+	dl   *DrawLooper
+
+	Colorize func(ri int) color.Color
+}
+
+func NewSyntaxLooper(strl *StringLooper, dl *DrawLooper) *SyntaxLooper {
+	return &SyntaxLooper{strl: strl, dl: dl}
+}
+func (lpr *SyntaxLooper) Loop(fn func() bool) {
+	if lpr.Colorize == nil {
+		lpr.OuterLooper().Loop(fn)
+		return
+	}
+	lpr.OuterLooper().Loop(func() bool {
+		if !lpr.strl.RiClone {
+			if c := lpr.Colorize(lpr.strl.Ri); c != nil {
+				lpr.dl.Fg = c
+			}
+		}
+		return fn()
+	})
+}
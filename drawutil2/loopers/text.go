@@ -0,0 +1,41 @@
+package loopers
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Text read by the loopers. Allows reading a text buffer without building the full string.
+type Text interface {
+	Len() int
+	Slice(i, j int) string
+	ReadRuneAt(i int) (rune, int)     // size zero at the end
+	ReadLastRuneAt(i int) (rune, int) // size zero at the start
+	LineStart(i int) int              // index after the newline before i, or zero
+	LineEnd(i int) int                // index of the newline at or after i, or the length
+}
+
+// String implementing Text.
+type StrText string
+
+func (s StrText) Len() int {
+	return len(s)
+}
+func (s StrText) Slice(i, j int) string {
+	return string(s[i:j])
+}
+func (s StrText) ReadRuneAt(i int) (rune, int) {
+	return utf8.DecodeRuneInString(string(s[i:]))
+}
+func (s StrText) ReadLastRuneAt(i int) (rune, int) {
+	return utf8.DecodeLastRuneInString(string(s[:i]))
+}
+func (s StrText) LineStart(i int) int {
+	return strings.LastIndexByte(string(s[:i]), '\n') + 1
+}
+func (s StrText) LineEnd(i int) int {
+	if j := strings.IndexByte(string(s[i:]), '\n'); j >= 0 {
+		return i + j
+	}
+	return len(s)
+}
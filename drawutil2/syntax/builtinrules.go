@@ -0,0 +1,127 @@
+package syntax
+
+// Rule sets available without configuration. Rule sets read from files with the same name take precedence.
+var BuiltinRuleSets = []*RuleSet{
+	shellRuleSet,
+	pythonRuleSet,
+	jsonRuleSet,
+	yamlRuleSet,
+	makeRuleSet,
+	cRuleSet,
+}
+
+const numberRegexp = `\b(?:0[xX][0-9a-fA-F]+|[0-9]+(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?)\b`
+
+var shellRuleSet = &RuleSet{
+	Name:       "shell",
+	Extensions: []string{".sh", ".bash", ".zsh", ".bashrc", ".profile"},
+	Shebangs:   []string{"sh", "bash", "zsh", "dash", "ksh"},
+	States: map[string]*RuleState{
+		"root": {Rules: []*Rule{
+			{Regexp: `\$(?:\{[^}]*\}|\w+|[#?@*$!0-9-])`, Kind: "builtin"},
+			{Regexp: `#.*`, Kind: "comment"},
+			{Regexp: `'[^']*'`, Kind: "string"},
+			{Regexp: `"`, Kind: "string", Next: "dq"},
+			{Regexp: `\b(?:if|then|else|elif|fi|for|while|until|do|done|case|esac|in|function|return|local|export|select|break|continue)\b`, Kind: "keyword"},
+			{Regexp: `\b(?:echo|cd|exit|test|read|set|unset|shift|eval|exec|source|printf|trap|true|false)\b`, Kind: "builtin"},
+			{Regexp: numberRegexp, Kind: "number"},
+		}},
+		// double quoted string, can span lines
+		"dq": {Kind: "string", Rules: []*Rule{
+			{Regexp: `\\.`},
+			{Regexp: `\$(?:\{[^}]*\}|\w+|[#?@*$!0-9-])`, Kind: "builtin"},
+			{Regexp: `"`, Next: "root"},
+		}},
+	},
+}
+
+var pythonRuleSet = &RuleSet{
+	Name:       "python",
+	Extensions: []string{".py", ".pyw"},
+	Shebangs:   []string{"python"},
+	States: map[string]*RuleState{
+		"root": {Rules: []*Rule{
+			{Regexp: `#.*`, Kind: "comment"},
+			{Regexp: `[rRbBuUfF]{0,2}"""`, Kind: "string", Next: "tdq"},
+			{Regexp: `[rRbBuUfF]{0,2}'''`, Kind: "string", Next: "tsq"},
+			{Regexp: `[rRbBuUfF]{0,2}"(?:[^"\\]|\\.)*"`, Kind: "string"},
+			{Regexp: `[rRbBuUfF]{0,2}'(?:[^'\\]|\\.)*'`, Kind: "string"},
+			{Regexp: `\b(?:and|as|assert|async|await|break|class|continue|def|del|elif|else|except|finally|for|from|global|if|import|in|is|lambda|nonlocal|not|or|pass|raise|return|try|while|with|yield)\b`, Kind: "keyword"},
+			{Regexp: `\b(?:True|False|None|self|print|len|range|int|str|float|list|dict|set|tuple|bool|object|super|isinstance|open|enumerate|zip|map|filter)\b`, Kind: "builtin"},
+			{Regexp: numberRegexp, Kind: "number"},
+		}},
+		// triple quoted strings
+		"tdq": {Kind: "string", Rules: []*Rule{
+			{Regexp: `\\.`},
+			{Regexp: `"""`, Next: "root"},
+		}},
+		"tsq": {Kind: "string", Rules: []*Rule{
+			{Regexp: `\\.`},
+			{Regexp: `'''`, Next: "root"},
+		}},
+	},
+}
+
+var jsonRuleSet = &RuleSet{
+	Name:       "json",
+	Extensions: []string{".json"},
+	States: map[string]*RuleState{
+		"root": {Rules: []*Rule{
+			{Regexp: `"(?:[^"\\]|\\.)*"`, Kind: "string"},
+			{Regexp: `\b(?:true|false|null)\b`, Kind: "keyword"},
+			{Regexp: `-?\b[0-9]+(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?\b`, Kind: "number"},
+		}},
+	},
+}
+
+var yamlRuleSet = &RuleSet{
+	Name:       "yaml",
+	Extensions: []string{".yaml", ".yml"},
+	States: map[string]*RuleState{
+		"root": {Rules: []*Rule{
+			{Regexp: `^(?:---|\.\.\.)`, Kind: "keyword"},
+			{Regexp: `(?:^|\s)#.*`, Kind: "comment"},
+			{Regexp: `"(?:[^"\\]|\\.)*"`, Kind: "string"},
+			{Regexp: `'(?:[^']|'')*'`, Kind: "string"},
+			{Regexp: `[\w.-]+\s*:(?:\s|$)`, Kind: "keyword"},
+			{Regexp: `[&*][\w-]+`, Kind: "builtin"},
+			{Regexp: `\b(?:true|false|yes|no|on|off|null)\b|~`, Kind: "builtin"},
+			{Regexp: numberRegexp, Kind: "number"},
+		}},
+	},
+}
+
+var makeRuleSet = &RuleSet{
+	Name:       "make",
+	Extensions: []string{".mk", "Makefile", "makefile", "GNUmakefile"},
+	Shebangs:   []string{"make"},
+	States: map[string]*RuleState{
+		"root": {Rules: []*Rule{
+			{Regexp: `#.*`, Kind: "comment"},
+			{Regexp: `\$(?:\([^)]*\)|\{[^}]*\}|[@<^?*%+])`, Kind: "builtin"},
+			{Regexp: `^(?:include|-include|sinclude|ifeq|ifneq|ifdef|ifndef|else|endif|define|endef|export|unexport|override|vpath)\b`, Kind: "keyword"},
+			{Regexp: `^[\w./%-]+(?:[ \t]+[\w./%-]+)*[ \t]*::?`, Kind: "keyword"},
+			{Regexp: `"(?:[^"\\]|\\.)*"|'[^']*'`, Kind: "string"},
+		}},
+	},
+}
+
+var cRuleSet = &RuleSet{
+	Name:       "c",
+	Extensions: []string{".c", ".h", ".cc", ".cpp", ".hpp", ".cxx"},
+	States: map[string]*RuleState{
+		"root": {Rules: []*Rule{
+			{Regexp: `//.*`, Kind: "comment"},
+			{Regexp: `/\*`, Kind: "comment", Next: "comment"},
+			{Regexp: `"(?:[^"\\]|\\.)*"`, Kind: "string"},
+			{Regexp: `'(?:[^'\\]|\\.)*'`, Kind: "string"},
+			{Regexp: `^\s*#\s*\w+`, Kind: "keyword"},
+			{Regexp: `\b(?:auto|break|case|const|continue|default|do|else|enum|extern|for|goto|if|inline|register|restrict|return|sizeof|static|struct|switch|typedef|union|volatile|while|class|namespace|template|public|private|protected|virtual|new|delete|using)\b`, Kind: "keyword"},
+			{Regexp: `\b(?:void|char|short|int|long|float|double|signed|unsigned|bool|size_t|NULL|true|false)\b`, Kind: "builtin"},
+			{Regexp: `\b(?:0[xX][0-9a-fA-F]+|[0-9]+(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?)[uUlLfF]*\b`, Kind: "number"},
+		}},
+		"comment": {Kind: "comment", Rules: []*Rule{
+			{Regexp: `\*/`, Next: "root"},
+		}},
+	},
+}
@@ -0,0 +1,59 @@
+package syntax
+
+// Comment style of a language, found like the rule sets by the filename extension or base name, or by the shebang.
+type CommentStyle struct {
+	Name       string
+	Extensions []string
+	Shebangs   []string
+	Line       string    // line comment prefix (ex: "#")
+	Block      [2]string // block comment start and end (ex: "/*", "*/"), used if there is no line prefix
+}
+
+var CommentStyles = []*CommentStyle{
+	{
+		Name:       "c",
+		Extensions: []string{".go", ".c", ".h", ".cc", ".cpp", ".hpp", ".cxx", ".java", ".js", ".jsx", ".ts", ".tsx", ".rs", ".swift", ".kt", ".scala", ".cs", ".proto"},
+		Line:       "//",
+	},
+	{
+		Name:       "hash",
+		Extensions: []string{".sh", ".bash", ".zsh", ".bashrc", ".profile", ".py", ".pyw", ".rb", ".pl", ".yaml", ".yml", ".toml", ".conf", ".mk", "Makefile", "makefile", "GNUmakefile", "Dockerfile", ".gitignore"},
+		Shebangs:   []string{"sh", "bash", "zsh", "dash", "ksh", "python", "ruby", "perl", "make"},
+		Line:       "#",
+	},
+	{
+		Name:       "dashes",
+		Extensions: []string{".sql", ".lua", ".hs"},
+		Shebangs:   []string{"lua"},
+		Line:       "--",
+	},
+	{
+		Name:       "semicolon",
+		Extensions: []string{".lisp", ".el", ".clj", ".scm", ".asm", ".ini"},
+		Line:       ";",
+	},
+	{
+		Name:       "css",
+		Extensions: []string{".css"},
+		Block:      [2]string{"/*", "*/"},
+	},
+	{
+		Name:       "html",
+		Extensions: []string{".html", ".htm", ".xml", ".svg"},
+		Block:      [2]string{"<!--", "-->"},
+	},
+}
+
+func FindCommentStyle(styles []*CommentStyle, filename, firstLine string) (*CommentStyle, bool) {
+	for _, cs := range styles {
+		if matchExtension(filename, cs.Extensions) {
+			return cs, true
+		}
+	}
+	for _, cs := range styles {
+		if matchShebang(firstLine, cs.Shebangs) {
+			return cs, true
+		}
+	}
+	return nil, false
+}
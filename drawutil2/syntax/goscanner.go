@@ -0,0 +1,118 @@
+package syntax
+
+import (
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// Go scanner based on go/scanner.
+type GoScanner struct{}
+
+const (
+	goNormal = iota
+	goInComment
+	goInRawString
+)
+
+func (GoScanner) ScanLine(line string, state int) ([]Token, int) {
+	var toks []Token
+
+	// continue constructs from previous lines
+	o := 0
+	switch state {
+	case goInComment:
+		i := strings.Index(line, "*/")
+		if i < 0 {
+			return []Token{{0, len(line), Comment}}, state
+		}
+		o = i + 2
+		toks = append(toks, Token{0, o, Comment})
+	case goInRawString:
+		i := strings.Index(line, "`")
+		if i < 0 {
+			return []Token{{0, len(line), String}}, state
+		}
+		o = i + 1
+		toks = append(toks, Token{0, o, String})
+	}
+	state = goNormal
+
+	src := line[o:]
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), func(token.Position, string) {}, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		kind := goKind(tok, lit)
+		if kind == None {
+			continue
+		}
+		start := o + file.Offset(pos)
+		end := start + len(lit)
+
+		// comments and raw strings literals can have carriage returns removed, use the line
+		switch {
+		case tok == token.COMMENT && strings.HasPrefix(lit, "//"):
+			end = len(line)
+		case tok == token.COMMENT:
+			end = len(line)
+			if i := strings.Index(line[start+2:], "*/"); i >= 0 {
+				end = start + 2 + i + 2
+			} else {
+				state = goInComment
+			}
+		case tok == token.STRING && lit[0] == '`':
+			end = len(line)
+			if i := strings.Index(line[start+1:], "`"); i >= 0 {
+				end = start + 1 + i + 1
+			} else {
+				state = goInRawString
+			}
+		}
+		if end > len(line) {
+			end = len(line)
+		}
+		toks = append(toks, Token{start, end, kind})
+	}
+	return toks, state
+}
+
+func goKind(tok token.Token, lit string) Kind {
+	switch {
+	case tok.IsKeyword():
+		return Keyword
+	case tok == token.STRING || tok == token.CHAR:
+		return String
+	case tok == token.COMMENT:
+		return Comment
+	case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+		return Number
+	case tok == token.IDENT && goBuiltins[lit]:
+		return Builtin
+	}
+	return None
+}
+
+var goBuiltins = map[string]bool{}
+
+func init() {
+	u := []string{
+		// types
+		"bool", "byte", "complex64", "complex128", "error", "float32", "float64",
+		"int", "int8", "int16", "int32", "int64", "rune", "string",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		// constants
+		"true", "false", "iota", "nil",
+		// functions
+		"append", "cap", "close", "complex", "copy", "delete", "imag", "len",
+		"make", "new", "panic", "print", "println", "real", "recover",
+	}
+	for _, s := range u {
+		goBuiltins[s] = true
+	}
+}
@@ -0,0 +1,207 @@
+// Syntax highlighting by lines.
+package syntax
+
+import "sort"
+
+type Kind int
+
+const (
+	None Kind = iota
+	Keyword
+	String
+	Comment
+	Number
+	Builtin
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Keyword:
+		return "keyword"
+	case String:
+		return "string"
+	case Comment:
+		return "comment"
+	case Number:
+		return "number"
+	case Builtin:
+		return "builtin"
+	}
+	return "none"
+}
+
+// Token range in the line.
+type Token struct {
+	Start, End int
+	Kind       Kind
+}
+
+// Language scanner. The state carries constructs that span lines (ex: block comments), 0 is the state at the start of the text.
+type Scanner interface {
+	ScanLine(line string, state int) (toks []Token, endState int)
+}
+
+// Text read by the highlighter, allows reading a text buffer without building the full string.
+type Text interface {
+	Len() int
+	Slice(i, j int) string
+	LineEnd(i int) int // index of the newline at or after i, or the length
+}
+
+// Keeps the tokens of each line. On changes, only the edited lines are scanned, plus the following lines while their start state changes. The lines start offsets are calculated when queried, edits only invalidate the ones after the edit.
+type Highlighter struct {
+	sc      Scanner
+	lines   []*hLine
+	starts  []int // line start offsets
+	nstarts int   // starts calculated, from the first line
+}
+
+type hLine struct {
+	n        int // length without the newline
+	state    int // state at the line start
+	endState int
+	toks     []Token
+}
+
+func NewHighlighter(sc Scanner) *Highlighter {
+	return &Highlighter{sc: sc}
+}
+
+// Scans the whole text.
+func (h *Highlighter) Update(text Text) {
+	h.lines = h.scanLines(text, 0, text.Len(), 0)
+	h.nstarts = 0
+}
+
+// The text was changed at [index,index+oldN) to [index,index+newN).
+func (h *Highlighter) Change(text Text, index, oldN, newN int) {
+	if len(h.lines) == 0 {
+		h.Update(text)
+		return
+	}
+
+	// lines of the old text affected by the change
+	li := h.lineAt(index)
+	lj := h.lineAt(index + oldN)
+
+	// new text of those lines
+	a := h.lineStart(li)
+	b := text.LineEnd(index + newN)
+	u := h.scanLines(text, a, b, h.lines[li].state)
+
+	h.lines = spliceLines(h.lines, li, lj+1, u)
+	if h.nstarts > li+1 {
+		h.nstarts = li + 1
+	}
+
+	// rescan the following lines while the state differs
+	for k := li + len(u); k < len(h.lines); k++ {
+		state := h.lines[k-1].endState
+		l := h.lines[k]
+		if l.state == state {
+			break
+		}
+		s := h.lineStart(k)
+		h.lines[k] = h.scanLine(text.Slice(s, s+l.n), state)
+	}
+}
+
+// Replaces lines[i:j] with u, moving the following lines only if the number of lines changed.
+func spliceLines(lines []*hLine, i, j int, u []*hLine) []*hLine {
+	d := len(u) - (j - i)
+	switch {
+	case d > 0:
+		lines = append(lines, make([]*hLine, d)...)
+		copy(lines[j+d:], lines[j:])
+	case d < 0:
+		n := len(lines)
+		copy(lines[j+d:], lines[j:])
+		for k := n + d; k < n; k++ {
+			lines[k] = nil // allow gc
+		}
+		lines = lines[:n+d]
+	}
+	copy(lines[i:], u)
+	return lines
+}
+
+// Scans the lines in [a,b), a is a line start and b a line end.
+func (h *Highlighter) scanLines(text Text, a, b, state int) []*hLine {
+	var lines []*hLine
+	for {
+		e := text.LineEnd(a)
+		if e > b {
+			e = b
+		}
+		l := h.scanLine(text.Slice(a, e), state)
+		lines = append(lines, l)
+		state = l.endState
+		if e >= b {
+			return lines
+		}
+		a = e + 1
+	}
+}
+func (h *Highlighter) scanLine(s string, state int) *hLine {
+	toks, endState := h.sc.ScanLine(s, state)
+	return &hLine{n: len(s), state: state, endState: endState, toks: toks}
+}
+
+// Calculates the starts up to line k.
+func (h *Highlighter) lineStart(k int) int {
+	for h.nstarts <= k {
+		h.calcNextStart()
+	}
+	return h.starts[k]
+}
+func (h *Highlighter) calcNextStart() {
+	s := 0
+	if k := h.nstarts; k > 0 {
+		s = h.starts[k-1] + h.lines[k-1].n + 1
+	}
+	h.starts = append(h.starts[:h.nstarts], s)
+	h.nstarts++
+}
+
+func (h *Highlighter) lineAt(index int) int {
+	// calculate the starts up to the index
+	for h.nstarts < len(h.lines) {
+		k := h.nstarts - 1
+		if k >= 0 && h.starts[k]+h.lines[k].n >= index {
+			break
+		}
+		h.calcNextStart()
+	}
+	k := sort.Search(h.nstarts, func(i int) bool {
+		return h.starts[i] > index
+	})
+	if k > 0 {
+		k--
+	}
+	return k
+}
+
+// Kind of the token at the string index.
+func (h *Highlighter) KindAt(index int) Kind {
+	if len(h.lines) == 0 {
+		return None
+	}
+	k := h.lineAt(index)
+	rel := index - h.lineStart(k)
+	toks := h.lines[k].toks
+	i := sort.Search(len(toks), func(i int) bool {
+		return toks[i].End > rel
+	})
+	if i < len(toks) && toks[i].Start <= rel {
+		return toks[i].Kind
+	}
+	return None
+}
+
+// Tokens of the line (used by tests).
+func (h *Highlighter) LineTokens(line int) []Token {
+	if line < 0 || line >= len(h.lines) {
+		return nil
+	}
+	return h.lines[line].toks
+}
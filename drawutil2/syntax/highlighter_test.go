@@ -0,0 +1,90 @@
+package syntax
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jmigpin/editor/drawutil2/loopers"
+)
+
+var goSrc = "package main\n\n/* block\ncomment */\nfunc f() int {\n\ts := `raw\nstring` + \"a\"\n\treturn len(s) + 0x1f // end\n}\n"
+
+func TestGoScanner(t *testing.T) {
+	h := NewHighlighter(GoScanner{})
+	h.Update(loopers.StrText(goSrc))
+	tests := []struct {
+		line int
+		toks []Token
+	}{
+		{0, []Token{{0, 7, Keyword}}},
+		{2, []Token{{0, 8, Comment}}},
+		{3, []Token{{0, 10, Comment}}},
+		{4, []Token{{0, 4, Keyword}, {9, 12, Builtin}}},
+		{5, []Token{{6, 10, String}}},
+		{6, []Token{{0, 7, String}, {10, 13, String}}},
+		{7, []Token{{1, 7, Keyword}, {8, 11, Builtin}, {17, 21, Number}, {22, 28, Comment}}},
+	}
+	for _, tt := range tests {
+		toks := h.LineTokens(tt.line)
+		if !reflect.DeepEqual(toks, tt.toks) {
+			t.Errorf("line %v: %v", tt.line, toks)
+		}
+	}
+}
+
+func testHighlighterChange(t *testing.T, str string, index, oldN int, istr string) {
+	t.Helper()
+	h := NewHighlighter(GoScanner{})
+	h.Update(loopers.StrText(str))
+	str2 := str[:index] + istr + str[index+oldN:]
+	h.Change(loopers.StrText(str2), index, oldN, len(istr))
+
+	h2 := NewHighlighter(GoScanner{})
+	h2.Update(loopers.StrText(str2))
+	// starts are calculated when queried
+	h.lineStart(len(h.lines) - 1)
+	h2.lineStart(len(h2.lines) - 1)
+	if !reflect.DeepEqual(h.lines, h2.lines) || !reflect.DeepEqual(h.starts[:h.nstarts], h2.starts[:h2.nstarts]) {
+		t.Fatalf("change differs from update: %q", str2)
+	}
+}
+
+func TestHighlighterChange1(t *testing.T) {
+	// open a block comment that covers the rest
+	testHighlighterChange(t, goSrc, 0, 0, "/*")
+}
+func TestHighlighterChange2(t *testing.T) {
+	// close the block comment early
+	testHighlighterChange(t, goSrc, 9, 0, "*/")
+}
+func TestHighlighterChange3(t *testing.T) {
+	// delete across lines
+	testHighlighterChange(t, goSrc, 5, 30, "")
+}
+func TestHighlighterChange4(t *testing.T) {
+	testHighlighterChange(t, goSrc, len(goSrc), 0, "var a = `\n")
+}
+
+func TestHighlighterChangeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pieces := []string{"a", "\n", "/*", "*/", "`", "\"", "// x", " "}
+	str := goSrc
+	h := NewHighlighter(GoScanner{})
+	h.Update(loopers.StrText(str))
+	for n := 0; n < 300; n++ {
+		index := r.Intn(len(str) + 1)
+		oldN := r.Intn(len(str)-index+1) % 6
+		istr := pieces[r.Intn(len(pieces))]
+		str = str[:index] + istr + str[index+oldN:]
+		h.Change(loopers.StrText(str), index, oldN, len(istr))
+
+		// queries calculate part of the starts
+		h2 := NewHighlighter(GoScanner{})
+		h2.Update(loopers.StrText(str))
+		i := r.Intn(len(str) + 1)
+		if h.KindAt(i) != h2.KindAt(i) || !reflect.DeepEqual(h.lines, h2.lines) {
+			t.Fatalf("kind at %v: %q", i, str)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package syntax
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Highlighting rules for a language. Loaded from json files with the same field names.
+type RuleSet struct {
+	Name       string
+	Extensions []string // extensions (".py") or base names ("Makefile")
+	Shebangs   []string // interpreter names (ex: "python" matches "#!/usr/bin/env python3")
+	States     map[string]*RuleState
+}
+
+// The "root" state is the state at the start of the text.
+type RuleState struct {
+	Kind  string // kind of the text not matched by the rules
+	Rules []*Rule
+}
+
+// The earliest match in the line wins, with ties going to the first rule.
+type Rule struct {
+	Regexp string
+	Kind   string // empty uses the state kind
+	Next   string // state after the match, empty keeps the state
+}
+
+// Scanner from a rule set.
+type RuleScanner struct {
+	states []*ruleScannerState // index is the scanner state
+	nrules int
+}
+
+type ruleScannerState struct {
+	kind  Kind
+	rules []*ruleScannerRule
+}
+
+type ruleScannerRule struct {
+	id   int // index in the line matches
+	re   *regexp.Regexp
+	kind Kind
+	next int
+	keep bool // next is the rule state, empty matches are ignored
+}
+
+func NewRuleScanner(rs *RuleSet) (*RuleScanner, error) {
+	if _, ok := rs.States["root"]; !ok {
+		return nil, fmt.Errorf("%v: missing root state", rs.Name)
+	}
+
+	// state indexes, root is zero
+	names := []string{"root"}
+	for name := range rs.States {
+		if name != "root" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	index := make(map[string]int)
+	for i, name := range names {
+		index[name] = i
+	}
+
+	sc := &RuleScanner{}
+	for _, name := range names {
+		st := rs.States[name]
+		kind, err := parseKind(st.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v: %v", rs.Name, name, err)
+		}
+		sst := &ruleScannerState{kind: kind}
+		for _, r := range st.Rules {
+			re, err := regexp.Compile(r.Regexp)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v: %v", rs.Name, name, err)
+			}
+			kind := sst.kind
+			if r.Kind != "" {
+				kind, err = parseKind(r.Kind)
+				if err != nil {
+					return nil, fmt.Errorf("%v: %v: %v", rs.Name, name, err)
+				}
+			}
+			next, ok := index[r.Next]
+			if r.Next == "" {
+				next = index[name]
+			} else if !ok {
+				return nil, fmt.Errorf("%v: %v: unknown state: %v", rs.Name, name, r.Next)
+			}
+			r2 := &ruleScannerRule{id: sc.nrules, re: re, kind: kind, next: next, keep: next == index[name]}
+			sc.nrules++
+			sst.rules = append(sst.rules, r2)
+		}
+		sc.states = append(sc.states, sst)
+	}
+	return sc, nil
+}
+
+func (sc *RuleScanner) ScanLine(line string, state int) ([]Token, int) {
+	if state < 0 || state >= len(sc.states) {
+		state = 0
+	}
+	var toks []Token
+	add := func(s, e int, kind Kind) {
+		if s == e || kind == None {
+			return
+		}
+		// merge with the previous token
+		if n := len(toks); n > 0 && toks[n-1].End == s && toks[n-1].Kind == kind {
+			toks[n-1].End = e
+			return
+		}
+		toks = append(toks, Token{s, e, kind})
+	}
+
+	matches := make([]ruleMatches, sc.nrules)
+	for p := 0; p < len(line); {
+		st := sc.states[state]
+
+		// earliest match
+		var rule *ruleScannerRule
+		var loc []int
+		for _, r := range st.rules {
+			l := matches[r.id].next(r, line, p)
+			if l == nil {
+				continue
+			}
+			if loc == nil || l[0] < loc[0] {
+				rule, loc = r, l
+			}
+		}
+		if rule == nil {
+			add(p, len(line), st.kind)
+			break
+		}
+		s, e := loc[0], loc[1]
+		matches[rule.id].use(loc)
+		add(p, s, st.kind)
+		add(s, e, rule.kind)
+		p = e
+		state = rule.next
+	}
+	return toks, state
+}
+
+// Matches of a rule in the line. The full line is searched so that anchors (ex: ^, \b) only match where they would in the line, and the matches are kept for the next positions.
+type ruleMatches struct {
+	locs   [][]int
+	i      int // first match that can start at or after the position
+	search bool
+	used   int // position+1 of the last used empty match
+}
+
+// First match that starts at or after p.
+func (m *ruleMatches) next(r *ruleScannerRule, line string, p int) []int {
+	if !m.search {
+		m.search = true
+		m.setLocs(r, r.re.FindAllStringIndex(line, -1), 0)
+	}
+	for ; m.i < len(m.locs); m.i++ {
+		loc := m.locs[m.i]
+		if loc[0] == loc[1] && loc[0]+1 == m.used {
+			continue // already used at this position (state switching loop)
+		}
+		if loc[0] >= p {
+			return loc
+		}
+		if loc[1] > p {
+			// p is inside a match that wasn't used, search again after p
+			m.setLocs(r, r.re.FindAllStringIndex(line[p:], -1), p)
+			return m.next(r, line, p)
+		}
+	}
+	return nil
+}
+func (m *ruleMatches) use(loc []int) {
+	m.i++
+	if loc[0] == loc[1] {
+		m.used = loc[0] + 1
+	}
+}
+func (m *ruleMatches) setLocs(r *ruleScannerRule, locs [][]int, offset int) {
+	m.locs, m.i = m.locs[:0], 0
+	for _, loc := range locs {
+		// empty matches are only useful to change state
+		if r.keep && loc[0] == loc[1] {
+			continue
+		}
+		m.locs = append(m.locs, []int{offset + loc[0], offset + loc[1]})
+	}
+}
+
+func parseKind(s string) (Kind, error) {
+	switch s {
+	case "":
+		return None, nil
+	case "keyword":
+		return Keyword, nil
+	case "string":
+		return String, nil
+	case "comment":
+		return Comment, nil
+	case "number":
+		return Number, nil
+	case "builtin":
+		return Builtin, nil
+	}
+	return None, fmt.Errorf("unknown kind: %v", s)
+}
+
+// Reads the *.json rule sets in the directory. A missing directory is not an error.
+func ReadRuleSets(dir string) ([]*RuleSet, error) {
+	filenames, err := filepath.Glob(path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+	var u []*RuleSet
+	for _, filename := range filenames {
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		rs := &RuleSet{}
+		if err := json.Unmarshal(b, rs); err != nil {
+			return nil, fmt.Errorf("%v: %v", filename, err)
+		}
+		if rs.Name == "" {
+			rs.Name = strings.TrimSuffix(path.Base(filename), ".json")
+		}
+		// check the rules
+		if _, err := NewRuleScanner(rs); err != nil {
+			return nil, fmt.Errorf("%v: %v", filename, err)
+		}
+		u = append(u, rs)
+	}
+	return u, nil
+}
+
+// Finds the rule set by the filename extension or base name, or by the shebang in the first line of the content.
+func FindRuleSet(sets []*RuleSet, filename, firstLine string) (*RuleSet, bool) {
+	for _, rs := range sets {
+		if matchExtension(filename, rs.Extensions) {
+			return rs, true
+		}
+	}
+	for _, rs := range sets {
+		if matchShebang(firstLine, rs.Shebangs) {
+			return rs, true
+		}
+	}
+	return nil, false
+}
+
+func matchExtension(filename string, exts []string) bool {
+	base := path.Base(filename)
+	ext := path.Ext(filename)
+	for _, e := range exts {
+		if (ext != "" && e == ext) || e == base {
+			return true
+		}
+	}
+	return false
+}
+func matchShebang(firstLine string, shebangs []string) bool {
+	interp, ok := shebangInterpreter(firstLine)
+	if !ok {
+		return false
+	}
+	for _, s := range shebangs {
+		if strings.HasPrefix(interp, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// "#!/usr/bin/env python3" gives "python3".
+func shebangInterpreter(line string) (string, bool) {
+	if !strings.HasPrefix(line, "#!") {
+		return "", false
+	}
+	f := strings.Fields(line[2:])
+	if len(f) == 0 {
+		return "", false
+	}
+	interp := path.Base(f[0])
+	if interp == "env" {
+		if len(f) < 2 {
+			return "", false
+		}
+		interp = f[1]
+	}
+	return interp, true
+}
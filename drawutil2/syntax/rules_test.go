@@ -0,0 +1,157 @@
+package syntax
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmigpin/editor/drawutil2/loopers"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// Tokens of the testdata files are checked against the .golden files. Run with -update to rewrite them.
+func TestRuleSetsGolden(t *testing.T) {
+	filenames, err := filepath.Glob("testdata/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, filename := range filenames {
+		if strings.HasSuffix(filename, ".golden") {
+			continue
+		}
+		n++
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		str := string(b)
+		firstLine := strings.SplitN(str, "\n", 2)[0]
+		rs, ok := FindRuleSet(BuiltinRuleSets, filename, firstLine)
+		if !ok {
+			t.Errorf("%v: rule set not found", filename)
+			continue
+		}
+		sc, err := NewRuleScanner(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := tokensOutput(sc, str)
+
+		golden := filename + ".golden"
+		if *updateGolden {
+			if err := ioutil.WriteFile(golden, []byte(out), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		b2, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != string(b2) {
+			t.Errorf("%v: output differs from %v:\n%v", filename, golden, out)
+		}
+	}
+	if n != len(BuiltinRuleSets) {
+		t.Errorf("expecting one testdata file per rule set: %v", n)
+	}
+}
+
+// One token per line: "line:start-end kind text".
+func tokensOutput(sc Scanner, str string) string {
+	h := NewHighlighter(sc)
+	h.Update(loopers.StrText(str))
+	var buf bytes.Buffer
+	for i, line := range strings.Split(str, "\n") {
+		for _, tok := range h.LineTokens(i) {
+			fmt.Fprintf(&buf, "%d:%d-%d %v %q\n", i+1, tok.Start, tok.End, tok.Kind, line[tok.Start:tok.End])
+		}
+	}
+	return buf.String()
+}
+
+func TestReadRuleSets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syntaxtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := `{"Extensions":[".x"],"States":{"root":{"Rules":[{"Regexp":"\\bfoo\\b","Kind":"keyword"}]}}}`
+	if err := ioutil.WriteFile(path.Join(dir, "xlang.json"), []byte(s), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sets, err := ReadRuleSets(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, ok := FindRuleSet(sets, "/a/b.x", "")
+	if !ok || rs.Name != "xlang" {
+		t.Fatalf("%v %v", rs, ok)
+	}
+	sc, err := NewRuleScanner(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toks, _ := sc.ScanLine("a foo b", 0)
+	if len(toks) != 1 || toks[0] != (Token{2, 5, Keyword}) {
+		t.Fatalf("%v", toks)
+	}
+
+	// bad kind
+	s = `{"States":{"root":{"Rules":[{"Regexp":"a","Kind":"nokind"}]}}}`
+	if err := ioutil.WriteFile(path.Join(dir, "bad.json"), []byte(s), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadRuleSets(dir); err == nil {
+		t.Fatal("expecting error")
+	}
+}
+
+func TestRuleScannerAnchors(t *testing.T) {
+	// "^" and "\b" don't match after a previous token
+	rs := &RuleSet{States: map[string]*RuleState{"root": {Rules: []*Rule{
+		{Regexp: "^#", Kind: "comment"},
+		{Regexp: `\bfoo`, Kind: "keyword"},
+		{Regexp: `[0-9]+`, Kind: "number"},
+	}}}}
+	sc, err := NewRuleScanner(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toks, _ := sc.ScanLine("#1#2foo 3foo foo", 0)
+	w := []Token{{0, 1, Comment}, {1, 2, Number}, {3, 4, Number}, {8, 9, Number}, {13, 16, Keyword}}
+	if !reflect.DeepEqual(toks, w) {
+		t.Fatalf("%v", toks)
+	}
+}
+
+func TestRuleScannerEmptyStateSwitch(t *testing.T) {
+	// empty matches switching between states don't loop at the same position
+	rs := &RuleSet{States: map[string]*RuleState{
+		"root": {Rules: []*Rule{
+			{Regexp: `\b`, Next: "word"},
+		}},
+		"word": {Kind: "string", Rules: []*Rule{
+			{Regexp: `\b`, Next: "root"},
+		}},
+	}}
+	sc, err := NewRuleScanner(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ends in the "word" state after the last "\b"
+	toks, state := sc.ScanLine("ab cd", 0)
+	if len(toks) != 0 || state != 1 {
+		t.Fatalf("%v %v", toks, state)
+	}
+}
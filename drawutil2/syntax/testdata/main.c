@@ -0,0 +1,7 @@
+#include <stdio.h>
+/* multi
+ line */
+static int f(char c) {
+	return c == 'a' ? 0x10u : 2.5f; // comment
+}
+int main(void) { printf("%d\n", f('b')); return 0; }
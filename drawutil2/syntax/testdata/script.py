@@ -0,0 +1,8 @@
+#!/usr/bin/env python3
+import os
+
+def f(x, y=0x1f):
+    """doc
+    string"""
+    return len(x) + 3.5e2  # comment
+s = r'raw\d' + "esc\"aped"
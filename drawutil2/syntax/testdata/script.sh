@@ -0,0 +1,7 @@
+#!/bin/sh
+# comment
+for f in *.go; do
+	echo "file: $f ${HOME}" 'single $x' $# 42
+done
+msg="multi
+line $USER"
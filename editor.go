@@ -21,9 +21,12 @@ func main() {
 	dpiFlag := flag.Float64("dpi", 72, "monitor dots per inch")
 	scrollbarWidth := flag.Int("scrollbarwidth", 12, "textarea scrollbar width")
 	acmeColors := flag.Bool("acmecolors", false, "acme editor color theme")
+	theme := flag.String("theme", "", "color theme: light, dark, acme, solarized")
 	wrapLineRune := flag.Int("wraplinerune", 8594, "code for wrap line rune")
 	tabWidth := flag.Int("tabwidth", 8, "")
 	scrollbarLeft := flag.Bool("scrollbarleft", false, "set scrollbars on the left side")
+	lineNumbers := flag.Bool("linenumbers", false, "show line numbers")
+	configFilename := flag.String("config", "", "config filename (default \"~/.config/editor/config.json\")")
 
 	flag.Parse()
 
@@ -36,15 +39,25 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	// flags given in the command line take precedence over the config
+	setFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
 	eopt := &core.Options{
 		FontFilename:   *fontFilenameFlag,
 		FontSize:       *fontSizeFlag,
 		DPI:            *dpiFlag,
 		ScrollbarWidth: *scrollbarWidth,
 		AcmeColors:     *acmeColors,
+		Theme:          *theme,
 		WrapLineRune:   *wrapLineRune,
 		TabWidth:       *tabWidth,
 		ScrollbarLeft:  *scrollbarLeft,
+		LineNumbers:    *lineNumbers,
+		ConfigFilename: *configFilename,
+		SetFlags:       setFlags,
 	}
 	_, err := core.NewEditor(eopt)
 	if err != nil {
@@ -1,7 +1,9 @@
 package ui
 
 import (
+	"fmt"
 	"image/color"
+	"sort"
 
 	"github.com/jmigpin/editor/drawutil2/hsdrawer"
 	"github.com/jmigpin/editor/imageutil"
@@ -18,44 +20,225 @@ var (
 	Yellow color.Color = color.RGBA{255, 153, 0, 255}
 	Green  color.Color = color.RGBA{15, 173, 0, 255}
 	Blue   color.Color = color.RGBA{0, 100, 181, 255}
+)
 
-	SeparatorColor = Black
+// Colors in use, set by SetTheme. Read at paint time.
+var (
+	TextAreaColors hsdrawer.Colors
+	ToolbarColors  hsdrawer.Colors
 
-	RowInnerSeparatorColor = imageutil.Tint(Blue, 0.50)
+	SeparatorColor         color.Color
+	RowInnerSeparatorColor color.Color
+	EmptySpaceColor        color.Color // columns without rows
 
-	SquareColor            = ToolbarColors.Normal.Bg
-	SquareActiveColor      = Black
-	SquareExecutingColor   = Green
-	SquareEditedColor      = Blue
-	SquareDiskChangesColor = Red
-	SquareNotExistColor    = Yellow
+	SquareColor            color.Color
+	SquareActiveColor      color.Color
+	SquareExecutingColor   color.Color
+	SquareEditedColor      color.Color
+	SquareDiskChangesColor color.Color
+	SquareNotExistColor    color.Color
 
-	ScrollbarFgColor = color.Color(imageutil.Tint(Black, 0.70))
-	ScrollbarBgColor = color.Color(imageutil.Tint(Black, 0.95))
+	ScrollbarFgColor color.Color
+	ScrollbarBgColor color.Color
 )
 
-var TextAreaColors = hsdrawer.Colors{
-	Normal:    hsdrawer.FgBg{Black, White},
-	Selection: hsdrawer.FgBg{nil, imageutil.Tint(Yellow, 0.50)},
-	Highlight: hsdrawer.FgBg{nil, imageutil.Tint(Blue, 0.70)},
+type Theme struct {
+	Name string
+
+	TextArea hsdrawer.Colors
+	Toolbar  hsdrawer.Colors
+
+	Separator         color.Color
+	RowInnerSeparator color.Color
+	EmptySpace        color.Color
+
+	Square            color.Color
+	SquareActive      color.Color
+	SquareExecuting   color.Color
+	SquareEdited      color.Color
+	SquareDiskChanges color.Color
+	SquareNotExist    color.Color
+
+	ScrollbarFg color.Color
+	ScrollbarBg color.Color
 }
 
-var ToolbarColors = hsdrawer.Colors{
-	Normal:    hsdrawer.FgBg{Black, imageutil.Tint(Black, 0.95)},
-	Selection: hsdrawer.FgBg{nil, TextAreaColors.Selection.Bg},
+func (t *Theme) set() {
+	TextAreaColors = t.TextArea
+	ToolbarColors = t.Toolbar
+	SeparatorColor = t.Separator
+	RowInnerSeparatorColor = t.RowInnerSeparator
+	EmptySpaceColor = t.EmptySpace
+	SquareColor = t.Square
+	SquareActiveColor = t.SquareActive
+	SquareExecutingColor = t.SquareExecuting
+	SquareEditedColor = t.SquareEdited
+	SquareDiskChangesColor = t.SquareDiskChanges
+	SquareNotExistColor = t.SquareNotExist
+	ScrollbarFgColor = t.ScrollbarFg
+	ScrollbarBgColor = t.ScrollbarBg
+}
+
+var Themes = map[string]*Theme{}
+
+func init() {
+	for _, t := range []*Theme{lightTheme, darkTheme, acmeTheme, solarizedTheme} {
+		Themes[t.Name] = t
+	}
+	lightTheme.set()
+}
+
+// Names of the available themes, sorted.
+func ThemeNames() []string {
+	var u []string
+	for name := range Themes {
+		u = append(u, name)
+	}
+	sort.Strings(u)
+	return u
 }
 
-func AcmeColors() {
-	TextAreaColors = hsdrawer.Colors{
-		Normal:    hsdrawer.FgBg{Black, color.RGBA{255, 255, 234, 255}},
+// Sets the colors of a named theme ("" or "default" is the light theme). Containers need to be painted again.
+func SetTheme(name string) error {
+	if name == "" || name == "default" {
+		name = lightTheme.Name
+	}
+	t, ok := Themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme: %v", name)
+	}
+	t.set()
+	return nil
+}
+
+var lightTheme = func() *Theme {
+	t := &Theme{Name: "light"}
+	t.TextArea = hsdrawer.Colors{
+		Normal:    hsdrawer.FgBg{Black, White},
 		Selection: hsdrawer.FgBg{nil, imageutil.Tint(Yellow, 0.50)},
 		Highlight: hsdrawer.FgBg{nil, imageutil.Tint(Blue, 0.70)},
+		Bracket:   hsdrawer.FgBg{nil, imageutil.Tint(Green, 0.60)},
+		Cursor:    Black,
+		Syntax: hsdrawer.SyntaxColors{
+			Keyword: imageutil.Shade(Blue, 0.80),
+			String:  imageutil.Shade(Green, 0.70),
+			Comment: imageutil.Tint(Black, 0.50),
+			Number:  imageutil.Shade(Red, 0.70),
+			Builtin: imageutil.Shade(Yellow, 0.70),
+		},
 	}
-	ToolbarColors = hsdrawer.Colors{
-		Normal:    hsdrawer.FgBg{Black, color.RGBA{234, 255, 255, 255}},
-		Selection: hsdrawer.FgBg{nil, TextAreaColors.Selection.Bg},
+	t.Toolbar = hsdrawer.Colors{
+		Normal:    hsdrawer.FgBg{Black, imageutil.Tint(Black, 0.95)},
+		Selection: hsdrawer.FgBg{nil, t.TextArea.Selection.Bg},
+		Cursor:    Black,
 	}
-	SquareColor = ToolbarColors.Normal.Bg
-	ScrollbarFgColor = color.RGBA{153, 153, 76, 255}
-	ScrollbarBgColor = imageutil.Shade(TextAreaColors.Normal.Bg, 0.90)
-}
+	t.Separator = Black
+	t.RowInnerSeparator = imageutil.Tint(Blue, 0.50)
+	t.EmptySpace = White
+	t.Square = t.Toolbar.Normal.Bg
+	t.SquareActive = Black
+	t.SquareExecuting = Green
+	t.SquareEdited = Blue
+	t.SquareDiskChanges = Red
+	t.SquareNotExist = Yellow
+	t.ScrollbarFg = imageutil.Tint(Black, 0.70)
+	t.ScrollbarBg = imageutil.Tint(Black, 0.95)
+	return t
+}()
+
+var acmeTheme = func() *Theme {
+	t := *lightTheme
+	t.Name = "acme"
+	t.TextArea.Normal.Bg = color.RGBA{255, 255, 234, 255}
+	t.Toolbar.Normal.Bg = color.RGBA{234, 255, 255, 255}
+	t.Square = t.Toolbar.Normal.Bg
+	t.ScrollbarFg = color.RGBA{153, 153, 76, 255}
+	t.ScrollbarBg = imageutil.Shade(t.TextArea.Normal.Bg, 0.90)
+	return &t
+}()
+
+var darkTheme = func() *Theme {
+	fg := color.RGBA{220, 220, 220, 255}
+	t := &Theme{Name: "dark"}
+	t.TextArea = hsdrawer.Colors{
+		Normal:    hsdrawer.FgBg{fg, color.RGBA{30, 30, 30, 255}},
+		Selection: hsdrawer.FgBg{nil, color.RGBA{100, 80, 30, 255}},
+		Highlight: hsdrawer.FgBg{nil, color.RGBA{35, 70, 105, 255}},
+		Bracket:   hsdrawer.FgBg{nil, color.RGBA{35, 90, 35, 255}},
+		Cursor:    fg,
+		Syntax: hsdrawer.SyntaxColors{
+			Keyword: color.RGBA{110, 160, 230, 255},
+			String:  color.RGBA{150, 200, 110, 255},
+			Comment: color.RGBA{128, 128, 128, 255},
+			Number:  color.RGBA{230, 140, 110, 255},
+			Builtin: color.RGBA{220, 190, 110, 255},
+		},
+	}
+	t.Toolbar = hsdrawer.Colors{
+		Normal:    hsdrawer.FgBg{fg, color.RGBA{50, 50, 50, 255}},
+		Selection: hsdrawer.FgBg{nil, t.TextArea.Selection.Bg},
+		Cursor:    fg,
+	}
+	t.Separator = Black
+	t.RowInnerSeparator = color.RGBA{60, 80, 110, 255}
+	t.EmptySpace = color.RGBA{20, 20, 20, 255}
+	t.Square = t.Toolbar.Normal.Bg
+	t.SquareActive = fg
+	t.SquareExecuting = Green
+	t.SquareEdited = color.RGBA{60, 130, 220, 255}
+	t.SquareDiskChanges = Red
+	t.SquareNotExist = Yellow
+	t.ScrollbarFg = color.RGBA{90, 90, 90, 255}
+	t.ScrollbarBg = color.RGBA{40, 40, 40, 255}
+	return t
+}()
+
+// Light variant of the solarized palette.
+var solarizedTheme = func() *Theme {
+	var (
+		base01  = color.RGBA{88, 110, 117, 255}
+		base00  = color.RGBA{101, 123, 131, 255}
+		base1   = color.RGBA{147, 161, 161, 255}
+		base2   = color.RGBA{238, 232, 213, 255}
+		base3   = color.RGBA{253, 246, 227, 255}
+		yellow  = color.RGBA{181, 137, 0, 255}
+		orange  = color.RGBA{203, 75, 22, 255}
+		red     = color.RGBA{220, 50, 47, 255}
+		magenta = color.RGBA{211, 54, 130, 255}
+		blue    = color.RGBA{38, 139, 210, 255}
+		cyan    = color.RGBA{42, 161, 152, 255}
+		green   = color.RGBA{133, 153, 0, 255}
+	)
+	t := &Theme{Name: "solarized"}
+	t.TextArea = hsdrawer.Colors{
+		Normal:    hsdrawer.FgBg{base00, base3},
+		Selection: hsdrawer.FgBg{nil, imageutil.Tint(yellow, 0.65)},
+		Highlight: hsdrawer.FgBg{nil, imageutil.Tint(blue, 0.70)},
+		Bracket:   hsdrawer.FgBg{nil, imageutil.Tint(green, 0.60)},
+		Cursor:    base01,
+		Syntax: hsdrawer.SyntaxColors{
+			Keyword: green,
+			String:  cyan,
+			Comment: base1,
+			Number:  magenta,
+			Builtin: yellow,
+		},
+	}
+	t.Toolbar = hsdrawer.Colors{
+		Normal:    hsdrawer.FgBg{base01, base2},
+		Selection: hsdrawer.FgBg{nil, t.TextArea.Selection.Bg},
+		Cursor:    base01,
+	}
+	t.Separator = base01
+	t.RowInnerSeparator = base1
+	t.EmptySpace = base3
+	t.Square = t.Toolbar.Normal.Bg
+	t.SquareActive = base01
+	t.SquareExecuting = green
+	t.SquareEdited = blue
+	t.SquareDiskChanges = red
+	t.SquareNotExist = orange
+	t.ScrollbarFg = base1
+	t.ScrollbarBg = base2
+	return t
+}()
@@ -1,8 +1,6 @@
 package ui
 
 import (
-	"image/color"
-
 	"github.com/BurntSushi/xgbutil/xcursor"
 	"github.com/jmigpin/editor/uiutil"
 	"github.com/jmigpin/editor/xgbutil/evreg"
@@ -33,7 +31,7 @@ func NewColumn(cols *Columns) *Column {
 	col.Square.EvReg.Add(SquareMotionNotifyEventId,
 		&evreg.Callback{col.onSquareMotionNotify})
 
-	col.colSep = NewSeparator(ui, SeparatorWidth, SeparatorColor)
+	col.colSep = NewSeparator(ui, SeparatorWidth, &SeparatorColor)
 
 	col.C.PaintFunc = col.paint
 	col.RowsC.Style.Direction = uiutil.ColumnDirection
@@ -56,7 +54,7 @@ func (col *Column) Close() {
 }
 func (col *Column) paint() {
 	if col.RowsC.NChilds == 0 {
-		col.Cols.Layout.UI.FillRectangle(&col.C.Bounds, color.White)
+		col.Cols.Layout.UI.FillRectangle(&col.C.Bounds, EmptySpaceColor)
 		return
 	}
 }
@@ -2,7 +2,6 @@ package ui
 
 import (
 	"image"
-	"image/color"
 
 	"github.com/jmigpin/editor/uiutil"
 )
@@ -23,7 +22,7 @@ func NewColumns(layout *Layout) *Columns {
 }
 func (cols *Columns) paint() {
 	if cols.C.NChilds == 0 {
-		cols.Layout.UI.FillRectangle(&cols.C.Bounds, color.White)
+		cols.Layout.UI.FillRectangle(&cols.C.Bounds, EmptySpaceColor)
 		return
 	}
 }
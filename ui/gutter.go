@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"image/color"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/jmigpin/editor/imageutil"
+	"github.com/jmigpin/editor/ui/tautil"
+	"github.com/jmigpin/editor/uiutil"
+	"github.com/jmigpin/editor/xgbutil/evreg"
+	"github.com/jmigpin/editor/xgbutil/xinput"
+)
+
+// Line numbers for the Textarea. Wrapped lines continuations are not numbered.
+type Gutter struct {
+	C       uiutil.Container
+	ta      *TextArea
+	digits  int
+	numbers []gutterNumber
+	evUnreg evreg.Unregister
+}
+
+type gutterNumber struct {
+	n int
+	y fixed.Int26_6 // relative to the textarea bounds
+}
+
+func NewGutter(ta *TextArea) *Gutter {
+	g := &Gutter{ta: ta}
+	g.C.Style.Hidden = !ShowLineNumbers
+	g.C.Style.DynamicMainSize = g.width
+	g.C.PaintFunc = g.paint
+
+	r1 := g.ta.ui.EvReg.Add(xinput.ButtonPressEventId,
+		&evreg.Callback{g.onButtonPress})
+	g.evUnreg.Add(r1)
+
+	// textarea set text
+	g.ta.EvReg.Add(TextAreaSetStrEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			g.updateDigits()
+			g.update()
+		}})
+	// textarea y jump
+	g.ta.EvReg.Add(TextAreaSetOffsetYEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			g.update()
+		}})
+	// textarea bounds change
+	g.ta.EvReg.Add(TextAreaBoundsChangeEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			g.update()
+		}})
+	// textarea remeasured (ex: folds or tab width change)
+	g.ta.EvReg.Add(TextAreaRemeasureEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			g.update()
+		}})
+
+	g.digits = g.calcDigits()
+	g.update()
+
+	return g
+}
+func (g *Gutter) Close() {
+	g.evUnreg.UnregisterAll()
+}
+
+// Shows or hides the line numbers.
+func (g *Gutter) SetVisible(v bool) {
+	h := &g.C.Style.Hidden
+	if *h == v {
+		*h = !v
+		g.update()
+		g.relayout()
+	}
+}
+func (g *Gutter) Visible() bool {
+	return !g.C.Style.Hidden
+}
+
+func (g *Gutter) calcDigits() int {
+	return len(strconv.Itoa(g.ta.Lines()))
+}
+
+// The width depends on the number of digits of the last line number.
+func (g *Gutter) updateDigits() {
+	d := g.calcDigits()
+	if d != g.digits {
+		g.digits = d
+		if g.Visible() {
+			g.relayout()
+		}
+	}
+}
+func (g *Gutter) relayout() {
+	if g.C.Parent != nil {
+		g.C.Parent.CalcChildsBounds()
+		g.C.Parent.NeedPaint()
+	}
+}
+
+func (g *Gutter) width() int {
+	digits := g.digits
+	if digits < 2 {
+		digits = 2
+	}
+	face := g.ta.ui.FontFace()
+	adv, _ := face.GlyphAdvance('0')
+	pad := adv.Ceil()
+	return (adv * fixed.Int26_6(digits)).Ceil() + pad
+}
+
+// Calculates the visible line numbers positions. Runs in the event loop since the drawer can't be used while the textarea is painting.
+func (g *Gutter) update() {
+	if !g.Visible() {
+		return
+	}
+	g.numbers = g.numbers[:0]
+	ta := g.ta
+	lh := ta.LineHeight()
+	if lh == 0 {
+		return
+	}
+
+	// first visible line
+	oy := ta.OffsetY()
+	y := oy / lh * lh
+
+	h := ta.StrHeight()
+	for ; y < h && (y-oy).Floor() < ta.C.Bounds.Dy(); y += lh {
+		p := fixed.Point26_6{X: 0, Y: y}
+		i := ta.drawer.GetIndex(&p)
+		if ru, _ := ta.buf.ReadLastRuneAt(i); i > 0 && ru != '\n' {
+			continue // wrapped line continuation
+		}
+		if g.isFoldPlaceholder(i) {
+			continue
+		}
+		n := ta.buf.LineAt(i)
+		g.numbers = append(g.numbers, gutterNumber{n, y - oy})
+	}
+	g.C.NeedPaint()
+}
+
+func (g *Gutter) paint() {
+	ta := g.ta
+	bg := imageutil.Shade(ta.Colors.Normal.Bg, 0.95)
+	ta.ui.FillRectangle(&g.C.Bounds, bg)
+
+	fg := ta.Colors.Syntax.Comment
+	if fg == nil {
+		fg = ta.Colors.Normal.Fg
+	}
+	for _, u := range g.numbers {
+		g.drawNumber(u.n, u.y, fg)
+	}
+}
+func (g *Gutter) isFoldPlaceholder(i int) bool {
+	for _, f := range g.ta.Folds() {
+		if f.Start == i {
+			return true
+		}
+	}
+	return false
+}
+
+// Draws the number right aligned at the y position relative to the bounds.
+func (g *Gutter) drawNumber(n int, y fixed.Int26_6, fg color.Color) {
+	face := g.ta.ui.FontFace()
+	bounds := &g.C.Bounds
+	s := strconv.Itoa(n)
+
+	adv, _ := face.GlyphAdvance('0')
+	pad := adv.Ceil() / 2
+	x := fixed.I(bounds.Dx()-pad) - font.MeasureString(face, s)
+	pen := fixed.Point26_6{
+		X: fixed.I(bounds.Min.X) + x,
+		Y: fixed.I(bounds.Min.Y) + y + face.Metrics().Ascent,
+	}
+	g.ta.ui.DrawString(bounds, pen, s, fg)
+}
+
+func (g *Gutter) onButtonPress(ev0 interface{}) {
+	ev := ev0.(*xinput.ButtonPressEvent)
+	if !ev.Point.In(g.C.Bounds) || g.C.Style.Hidden {
+		return
+	}
+	ta := g.ta
+	switch {
+	case ev.Button.Button(1):
+		// select the line of the clicked number
+		y := fixed.I(ev.Point.Y-g.C.Bounds.Min.Y) + ta.OffsetY()
+		p := fixed.Point26_6{X: 0, Y: y}
+		i := ta.PointIndex(&p)
+		ta.SetSelectionOff()
+		ta.SetCursorIndex(i)
+		tautil.SelectLine(ta)
+	case ev.Button.Button(4):
+		tautil.ScrollUp(ta)
+	case ev.Button.Button(5):
+		tautil.ScrollDown(ta)
+	}
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"sort"
+
+	"github.com/jmigpin/editor/ui/keymap"
+	"github.com/jmigpin/editor/ui/tautil"
+)
+
+// Key bindings of the textareas (and toolbars). Actions that are not textarea actions are emitted in a TextAreaKeyActionEvent (ex: toolbar commands).
+var TextAreaKeymap = NewDefaultKeymap()
+
+// Keymap with the default textarea bindings.
+func NewDefaultKeymap() *keymap.Keymap {
+	km, err := keymap.NewFromBindings(DefaultKeyBindings)
+	if err != nil {
+		panic(err)
+	}
+	return km
+}
+
+var DefaultKeyBindings = []keymap.Binding{
+	{"escape", "ClearExtraCursors"},
+
+	{"right", "MoveCursorRight"},
+	{"shift+right", "MoveCursorRightSelect"},
+	{"ctrl+right", "MoveCursorJumpRight"},
+	{"ctrl+shift+right", "MoveCursorJumpRightSelect"},
+	{"left", "MoveCursorLeft"},
+	{"shift+left", "MoveCursorLeftSelect"},
+	{"ctrl+left", "MoveCursorJumpLeft"},
+	{"ctrl+shift+left", "MoveCursorJumpLeftSelect"},
+	{"up", "MoveCursorUp"},
+	{"shift+up", "MoveCursorUpSelect"},
+	{"ctrl+alt+up", "MoveLineUp"},
+	{"down", "MoveCursorDown"},
+	{"shift+down", "MoveCursorDownSelect"},
+	{"ctrl+alt+down", "MoveLineDown"},
+	{"ctrl+shift+alt+down", "DuplicateLines"},
+	{"home", "StartOfLine"},
+	{"shift+home", "StartOfLineSelect"},
+	{"ctrl+home", "StartOfString"},
+	{"ctrl+shift+home", "StartOfStringSelect"},
+	{"end", "EndOfLine"},
+	{"shift+end", "EndOfLineSelect"},
+	{"ctrl+end", "EndOfString"},
+	{"ctrl+shift+end", "EndOfStringSelect"},
+	{"pageup", "PageUp"},
+	{"pagedown", "PageDown"},
+
+	{"backspace", "Backspace"},
+	{"shift+backspace", "Backspace"},
+	{"delete", "Delete"},
+	{"tab", "TabRight"},
+	{"shift+tab", "TabLeft"},
+	{"return", "AutoIndent"},
+
+	{"ctrl+d", "Comment"},
+	{"ctrl+shift+d", "Uncomment"},
+	{"ctrl+c", "Copy"},
+	{"ctrl+x", "Cut"},
+	{"ctrl+v", "PasteClipboard"},
+	{"ctrl+k", "RemoveLines"},
+	{"ctrl+a", "SelectAll"},
+	{"ctrl+z", "Undo"},
+	{"ctrl+shift+z", "Redo"},
+	{"ctrl+shift+l", "AddCursorsToLines"},
+	{"ctrl+shift+n", "AddCursorNextMatch"},
+	{"ctrl+b", "JumpToMatchingBracket"},
+	{"ctrl+shift+b", "SelectBetweenBrackets"},
+}
+
+// Actions that run on the textarea.
+var textAreaActions = map[string]func(ta *TextArea){
+	"ClearExtraCursors":         func(ta *TextArea) { tautil.ClearExtraCursors(ta) },
+	"MoveCursorRight":           func(ta *TextArea) { tautil.MoveCursorRight(ta, false) },
+	"MoveCursorRightSelect":     func(ta *TextArea) { tautil.MoveCursorRight(ta, true) },
+	"MoveCursorJumpRight":       func(ta *TextArea) { tautil.MoveCursorJumpRight(ta, false) },
+	"MoveCursorJumpRightSelect": func(ta *TextArea) { tautil.MoveCursorJumpRight(ta, true) },
+	"MoveCursorLeft":            func(ta *TextArea) { tautil.MoveCursorLeft(ta, false) },
+	"MoveCursorLeftSelect":      func(ta *TextArea) { tautil.MoveCursorLeft(ta, true) },
+	"MoveCursorJumpLeft":        func(ta *TextArea) { tautil.MoveCursorJumpLeft(ta, false) },
+	"MoveCursorJumpLeftSelect":  func(ta *TextArea) { tautil.MoveCursorJumpLeft(ta, true) },
+	"MoveCursorUp":              func(ta *TextArea) { tautil.MoveCursorUp(ta, false) },
+	"MoveCursorUpSelect":        func(ta *TextArea) { tautil.MoveCursorUp(ta, true) },
+	"MoveCursorDown":            func(ta *TextArea) { tautil.MoveCursorDown(ta, false) },
+	"MoveCursorDownSelect":      func(ta *TextArea) { tautil.MoveCursorDown(ta, true) },
+	"MoveLineUp":                func(ta *TextArea) { tautil.MoveLineUp(ta) },
+	"MoveLineDown":              func(ta *TextArea) { tautil.MoveLineDown(ta) },
+	"DuplicateLines":            func(ta *TextArea) { tautil.DuplicateLines(ta) },
+	"StartOfLine":               func(ta *TextArea) { tautil.StartOfLine(ta, false) },
+	"StartOfLineSelect":         func(ta *TextArea) { tautil.StartOfLine(ta, true) },
+	"StartOfString":             func(ta *TextArea) { tautil.StartOfString(ta, false) },
+	"StartOfStringSelect":       func(ta *TextArea) { tautil.StartOfString(ta, true) },
+	"EndOfLine":                 func(ta *TextArea) { tautil.EndOfLine(ta, false) },
+	"EndOfLineSelect":           func(ta *TextArea) { tautil.EndOfLine(ta, true) },
+	"EndOfString":               func(ta *TextArea) { tautil.EndOfString(ta, false) },
+	"EndOfStringSelect":         func(ta *TextArea) { tautil.EndOfString(ta, true) },
+	"PageUp":                    func(ta *TextArea) { ta.PageUp() },
+	"PageDown":                  func(ta *TextArea) { ta.PageDown() },
+	"ScrollUp":                  func(ta *TextArea) { tautil.ScrollUp(ta) },
+	"ScrollDown":                func(ta *TextArea) { tautil.ScrollDown(ta) },
+	"Backspace":                 func(ta *TextArea) { tautil.Backspace(ta) },
+	"Delete":                    func(ta *TextArea) { tautil.Delete(ta) },
+	"TabRight":                  func(ta *TextArea) { tautil.TabRight(ta) },
+	"TabLeft":                   func(ta *TextArea) { tautil.TabLeft(ta) },
+	"AutoIndent":                func(ta *TextArea) { tautil.AutoIndent(ta) },
+	"Comment":                   func(ta *TextArea) { tautil.Comment(ta) },
+	"Uncomment":                 func(ta *TextArea) { tautil.Uncomment(ta) },
+	"Copy":                      func(ta *TextArea) { tautil.Copy(ta) },
+	"Cut":                       func(ta *TextArea) { tautil.Cut(ta) },
+	"PasteClipboard":            func(ta *TextArea) { tautil.PasteClipboard(ta) },
+	"PastePrimary":              func(ta *TextArea) { tautil.PastePrimary(ta) },
+	"RemoveLines":               func(ta *TextArea) { tautil.RemoveLines(ta) },
+	"SelectAll":                 func(ta *TextArea) { tautil.SelectAll(ta) },
+	"SelectLine":                func(ta *TextArea) { tautil.SelectLine(ta) },
+	"SelectWord":                func(ta *TextArea) { tautil.SelectWord(ta) },
+	"Undo":                      func(ta *TextArea) { ta.Undo() },
+	"Redo":                      func(ta *TextArea) { ta.Redo() },
+	"AddCursorsToLines":         func(ta *TextArea) { tautil.AddCursorsToLines(ta) },
+	"AddCursorNextMatch":        func(ta *TextArea) { tautil.AddCursorNextMatch(ta) },
+	"JumpToMatchingBracket":     func(ta *TextArea) { tautil.JumpToMatchingBracket(ta) },
+	"SelectBetweenBrackets":     func(ta *TextArea) { tautil.SelectBetweenBrackets(ta) },
+}
+
+// Names of the textarea actions, sorted.
+func TextAreaActions() []string {
+	var u []string
+	for name := range textAreaActions {
+		u = append(u, name)
+	}
+	sort.Strings(u)
+	return u
+}
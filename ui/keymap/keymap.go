@@ -0,0 +1,141 @@
+// Key sequences to named actions.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmigpin/editor/xgbutil/xinput"
+)
+
+// Sequences of key chords mapped to actions.
+type Keymap struct {
+	root *node
+}
+
+type node struct {
+	action string
+	next   map[xinput.Chord]*node
+}
+
+type Binding struct {
+	Seq    string // chords separated by spaces (ex: "ctrl+x ctrl+s")
+	Action string
+}
+
+func New() *Keymap {
+	return &Keymap{root: &node{}}
+}
+
+func NewFromBindings(u []Binding) (*Keymap, error) {
+	km := New()
+	for _, b := range u {
+		if err := km.Bind(b.Seq, b.Action); err != nil {
+			return nil, err
+		}
+	}
+	return km, nil
+}
+
+// Binds the sequence to the action, replacing bindings that are a prefix of the sequence or start with it. An empty action removes the binding.
+func (km *Keymap) Bind(seq, action string) error {
+	cs, err := ParseSeq(seq)
+	if err != nil {
+		return err
+	}
+	n := km.root
+	for i, c := range cs {
+		if action != "" {
+			n.action = ""
+		}
+		nn, ok := n.next[c]
+		if !ok {
+			if action == "" {
+				return nil
+			}
+			nn = &node{}
+			if n.next == nil {
+				n.next = map[xinput.Chord]*node{}
+			}
+			n.next[c] = nn
+		}
+		if i == len(cs)-1 && action == "" {
+			delete(n.next, c)
+			return nil
+		}
+		n = nn
+	}
+	n.action = action
+	n.next = nil
+	return nil
+}
+
+func ParseSeq(seq string) ([]xinput.Chord, error) {
+	var cs []xinput.Chord
+	for _, s := range strings.Fields(seq) {
+		c, err := xinput.ParseChord(s)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("empty key sequence")
+	}
+	return cs, nil
+}
+
+// Bindings sorted by sequence.
+func (km *Keymap) Bindings() []Binding {
+	var u []Binding
+	var visit func(n *node, seq []string)
+	visit = func(n *node, seq []string) {
+		if n.action != "" {
+			u = append(u, Binding{strings.Join(seq, " "), n.action})
+		}
+		for c, nn := range n.next {
+			visit(nn, append(seq[:len(seq):len(seq)], c.String()))
+		}
+	}
+	visit(km.root, nil)
+	sort.Slice(u, func(i, j int) bool {
+		return u[i].Seq < u[j].Seq
+	})
+	return u
+}
+
+type Result int
+
+const (
+	NoMatch  Result = iota
+	Match           // action found
+	Pending         // sequence prefix, waiting for more keys
+	Canceled        // key not continuing a pending sequence
+)
+
+// Keeps the pending keys of a sequence (one per input target).
+type Matcher struct {
+	n *node
+}
+
+func (m *Matcher) Match(km *Keymap, c xinput.Chord) (string, Result) {
+	n := m.n
+	if n == nil {
+		n = km.root
+	}
+	nn, ok := n.next[c]
+	if !ok {
+		m.n = nil
+		if n != km.root {
+			return "", Canceled
+		}
+		return "", NoMatch
+	}
+	if nn.action != "" {
+		m.n = nil
+		return nn.action, Match
+	}
+	m.n = nn
+	return "", Pending
+}
@@ -0,0 +1,76 @@
+package keymap
+
+import (
+	"testing"
+
+	"github.com/jmigpin/editor/xgbutil/xinput"
+)
+
+func TestKeymapMatch(t *testing.T) {
+	km, err := NewFromBindings([]Binding{
+		{"ctrl+s", "Save"},
+		{"ctrl+x ctrl+f", "Find"},
+		{"ctrl+x k", "Close"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chord := func(s string) xinput.Chord {
+		c, err := xinput.ParseChord(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	var m Matcher
+	if a, r := m.Match(km, chord("ctrl+s")); r != Match || a != "Save" {
+		t.Fatal(a, r)
+	}
+	if _, r := m.Match(km, chord("ctrl+x")); r != Pending {
+		t.Fatal(r)
+	}
+	if a, r := m.Match(km, chord("k")); r != Match || a != "Close" {
+		t.Fatal(a, r)
+	}
+	if _, r := m.Match(km, chord("a")); r != NoMatch {
+		t.Fatal(r)
+	}
+	m.Match(km, chord("ctrl+x"))
+	if _, r := m.Match(km, chord("a")); r != Canceled {
+		t.Fatal(r)
+	}
+	// back at the root
+	if _, r := m.Match(km, chord("ctrl+s")); r != Match {
+		t.Fatal(r)
+	}
+}
+
+func TestKeymapBind(t *testing.T) {
+	km := New()
+	km.Bind("ctrl+x ctrl+f", "Find")
+	km.Bind("ctrl+x k", "Close")
+
+	// replaces the sequences starting with it
+	km.Bind("ctrl+x", "Cut")
+	u := km.Bindings()
+	if len(u) != 1 || u[0] != (Binding{"ctrl+x", "Cut"}) {
+		t.Fatal(u)
+	}
+	// replaces the prefix
+	km.Bind("ctrl+x ctrl+s", "Save")
+	km.Bind("escape", "ClearExtraCursors")
+	u = km.Bindings()
+	if len(u) != 2 || u[0] != (Binding{"ctrl+x ctrl+s", "Save"}) || u[1].Seq != "escape" {
+		t.Fatal(u)
+	}
+	// unbind
+	km.Bind("Ctrl+X Ctrl+S", "")
+	if u := km.Bindings(); len(u) != 1 {
+		t.Fatal(u)
+	}
+
+	if err := km.Bind("ctrl+nokey", "a"); err == nil {
+		t.Fatal("expecting error")
+	}
+}
@@ -16,7 +16,7 @@ func NewLayout(ui *UI) *Layout {
 
 	layout.Toolbar = NewToolbar(ui, &layout.C)
 
-	sep := NewSeparator(ui, SeparatorWidth, SeparatorColor)
+	sep := NewSeparator(ui, SeparatorWidth, &SeparatorColor)
 
 	layout.Cols = NewColumns(layout)
 
@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// List of items painted over the layout, below (or above) a textarea index. Keys are handled by the user (ex: completion).
+type Popup struct {
+	ui       *UI
+	ta       *TextArea
+	items    []string
+	selected int
+	top      int // first visible item
+	bounds   image.Rectangle
+	visible  bool
+
+	needPaint bool
+}
+
+const popupMaxItems = 10
+
+func NewPopup(ui *UI) *Popup {
+	return &Popup{ui: ui}
+}
+
+// Shows the items at the textarea index, selecting the first. Hidden if the index is not visible.
+func (p *Popup) Show(ta *TextArea, index int, items []string) {
+	if len(items) == 0 {
+		p.Hide()
+		return
+	}
+	p.ta = ta
+	p.items = items
+	p.selected = 0
+	p.top = 0
+	p.setBounds(index)
+}
+func (p *Popup) Hide() {
+	if p.visible {
+		p.visible = false
+		p.ui.Layout.C.NeedPaintRect(p.bounds)
+	}
+	p.ta = nil
+	p.items = nil
+}
+func (p *Popup) Visible() bool {
+	return p.visible
+}
+
+// Textarea the popup was shown for.
+func (p *Popup) TextArea() *TextArea {
+	return p.ta
+}
+
+func (p *Popup) Selected() (string, bool) {
+	if !p.visible {
+		return "", false
+	}
+	return p.items[p.selected], true
+}
+
+// Moves the selection by n items, wrapping around.
+func (p *Popup) Select(n int) {
+	if !p.visible {
+		return
+	}
+	l := len(p.items)
+	p.selected = ((p.selected+n)%l + l) % l
+	if p.selected < p.top {
+		p.top = p.selected
+	} else if p.selected >= p.top+popupMaxItems {
+		p.top = p.selected - popupMaxItems + 1
+	}
+	p.needPaint = true
+}
+
+// Runs in the event loop, the textarea drawer can't be used while painting.
+func (p *Popup) setBounds(index int) {
+	old, oldVisible := p.bounds, p.visible
+	p.visible = false
+	defer func() {
+		if oldVisible && (!p.visible || old != p.bounds) {
+			p.ui.Layout.C.NeedPaintRect(old)
+		}
+		p.needPaint = p.visible
+	}()
+
+	ta := p.ta
+	lh := ta.LineHeight().Ceil()
+	if lh == 0 {
+		return
+	}
+	pt := ta.drawer.GetPoint(index)
+	x := ta.C.Bounds.Min.X + pt.X.Round()
+	y := ta.C.Bounds.Min.Y + (pt.Y - ta.OffsetY()).Round()
+	if y < ta.C.Bounds.Min.Y || y+lh > ta.C.Bounds.Max.Y {
+		return
+	}
+
+	face := p.ui.FontFace()
+	w := fixed.Int26_6(0)
+	for _, s := range p.items {
+		if v := font.MeasureString(face, s); v > w {
+			w = v
+		}
+	}
+	pad := lh / 4
+	n := len(p.items)
+	if n > popupMaxItems {
+		n = popupMaxItems
+	}
+	size := image.Point{w.Ceil() + 2*pad + 2, n*lh + 2}
+
+	lb := p.ui.Layout.C.Bounds
+	r := image.Rectangle{image.Point{x, y + lh}, image.Point{x, y + lh}.Add(size)}
+	if r.Max.Y > lb.Max.Y {
+		// above the line
+		r = r.Sub(image.Point{0, lh + size.Y})
+	}
+	if r.Max.X > lb.Max.X {
+		r = r.Sub(image.Point{r.Max.X - lb.Max.X, 0})
+	}
+	r = r.Intersect(lb)
+	if r.Empty() {
+		return
+	}
+	p.bounds = r
+	p.visible = true
+}
+
+// Paints over the layout if the popup or the area under it was painted.
+func (p *Popup) paintIfNeeded(painted []*image.Rectangle) bool {
+	if !p.visible {
+		return false
+	}
+	need := p.needPaint
+	for _, r := range painted {
+		if r.Overlaps(p.bounds) {
+			need = true
+		}
+	}
+	if need {
+		p.needPaint = false
+		p.paint()
+	}
+	return need
+}
+func (p *Popup) paint() {
+	ui := p.ui
+	ui.FillRectangle(&p.bounds, SeparatorColor)
+	inner := p.bounds.Inset(1)
+	ui.FillRectangle(&inner, ToolbarColors.Normal.Bg)
+
+	face := ui.FontFace()
+	lh := p.ta.LineHeight().Ceil()
+	pad := lh / 4
+	for i := p.top; i < len(p.items) && i < p.top+popupMaxItems; i++ {
+		r := inner
+		r.Min.Y += (i - p.top) * lh
+		r.Max.Y = r.Min.Y + lh
+		r = r.Intersect(inner)
+		if i == p.selected {
+			ui.FillRectangle(&r, TextAreaColors.Selection.Bg)
+		}
+		pen := fixed.Point26_6{
+			X: fixed.I(r.Min.X + pad),
+			Y: fixed.I(r.Min.Y) + face.Metrics().Ascent,
+		}
+		ui.DrawString(&r, pen, p.items[i], ToolbarColors.Normal.Fg)
+	}
+}
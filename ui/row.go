@@ -14,6 +14,8 @@ type Row struct {
 	TextArea  *TextArea
 	Square    *Square
 	scrollbar *Scrollbar
+	Gutter    *Gutter
+	Status    *RowStatus
 	rowSep    *Separator
 	EvReg     *evreg.Register
 	evUnreg   evreg.Unregister
@@ -50,31 +52,33 @@ func NewRow(col *Column) *Row {
 	row.TextArea.Colors = &TextAreaColors
 
 	row.scrollbar = NewScrollbar(row.TextArea)
+	row.Gutter = NewGutter(row.TextArea)
+	row.Status = NewRowStatus(row.TextArea, &row.C)
 
 	// separators
 	sw := SeparatorWidth
-	row.rowSep = NewSeparator(ui, sw, SeparatorColor)
-	tbSep := NewSeparator(ui, sw, RowInnerSeparatorColor)
+	row.rowSep = NewSeparator(ui, sw, &SeparatorColor)
+	tbSep := NewSeparator(ui, sw, &RowInnerSeparatorColor)
 
 	// wrap containers
 	w1 := &uiutil.Container{}
 	if ScrollbarLeft {
-		w1.AppendChilds(&row.Square.C, &row.Toolbar.C)
+		w1.AppendChilds(&row.Square.C, &row.Toolbar.C, &row.Status.C)
 	} else {
-		w1.AppendChilds(&row.Toolbar.C, &row.Square.C)
+		w1.AppendChilds(&row.Toolbar.C, &row.Status.C, &row.Square.C)
 	}
 	w2 := &uiutil.Container{}
 	if ScrollbarLeft {
-		w2.AppendChilds(&row.scrollbar.C, &row.TextArea.C)
+		w2.AppendChilds(&row.scrollbar.C, &row.Gutter.C, &row.TextArea.C)
 	} else {
-		w2.AppendChilds(&row.TextArea.C, &row.scrollbar.C)
+		w2.AppendChilds(&row.Gutter.C, &row.TextArea.C, &row.scrollbar.C)
 	}
 	row.C.Style.Direction = uiutil.ColumnDirection
 	row.C.AppendChilds(&row.rowSep.C, w1, &tbSep.C, w2)
 
 	// dynamic toolbar bounds
 	w1.Style.DynamicMainSize = func() int {
-		dx := row.C.Bounds.Dx() - *row.Square.C.Style.MainSize
+		dx := row.C.Bounds.Dx() - *row.Square.C.Style.MainSize - row.Status.width
 		return row.Toolbar.CalcStringHeight(dx)
 	}
 
@@ -94,6 +98,7 @@ func (row *Row) Close() {
 	row.Col.removeRow(row)
 	row.evUnreg.UnregisterAll()
 	row.scrollbar.Close()
+	row.Gutter.Close()
 	row.Toolbar.Close()
 	row.TextArea.Close()
 	row.Square.Close()
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/jmigpin/editor/ui/tautil"
+	"github.com/jmigpin/editor/uiutil"
+	"github.com/jmigpin/editor/xgbutil/evreg"
+)
+
+// Cursor position readout of the row textarea: "line:col #offset", the selection size and the text size, followed by the info set by the user (ex: file format).
+type RowStatus struct {
+	C       uiutil.Container
+	ta      *TextArea
+	parentC *uiutil.Container
+	str     string
+	info    string
+	width   int // only grows, avoids relayouts while the cursor moves
+}
+
+func NewRowStatus(ta *TextArea, parentC *uiutil.Container) *RowStatus {
+	rs := &RowStatus{ta: ta, parentC: parentC}
+	rs.C.Style.DynamicMainSize = func() int { return rs.width }
+	rs.C.PaintFunc = rs.paint
+
+	// textarea cursor or selection change
+	rs.ta.EvReg.Add(TextAreaSetCursorIndexEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			rs.update()
+		}})
+	// textarea set text
+	rs.ta.EvReg.Add(TextAreaSetStrEventId,
+		&evreg.Callback{func(ev0 interface{}) {
+			rs.update()
+		}})
+
+	rs.str = rs.calcStr()
+	rs.width = rs.calcWidth(rs.str)
+	return rs
+}
+
+func (rs *RowStatus) calcStr() string {
+	ta := rs.ta
+	ci := ta.CursorIndex()
+	line, col := ta.IndexLineCol(ci)
+	s := fmt.Sprintf("%d:%d #%d", line, col, ci)
+	if ta.SelectionOn() {
+		a, b := tautil.SelectionStringIndexes(ta)
+		s += fmt.Sprintf(" sel %d", b-a)
+	}
+	s += fmt.Sprintf(" %dB", ta.buf.Len())
+	if rs.info != "" {
+		s += " " + rs.info
+	}
+	return s
+}
+func (rs *RowStatus) calcWidth(s string) int {
+	face := rs.ta.ui.FontFace()
+	adv, _ := face.GlyphAdvance('0')
+	return (font.MeasureString(face, s) + adv).Ceil()
+}
+
+func (rs *RowStatus) SetInfo(s string) {
+	rs.info = s
+	rs.update()
+}
+
+func (rs *RowStatus) update() {
+	s := rs.calcStr()
+	if s == rs.str {
+		return
+	}
+	rs.str = s
+	if w := rs.calcWidth(s); w > rs.width {
+		rs.width = w
+		rs.parentC.CalcChildsBounds()
+		rs.parentC.NeedPaint()
+		return
+	}
+	rs.C.NeedPaint()
+}
+
+func (rs *RowStatus) paint() {
+	bounds := &rs.C.Bounds
+	c := &ToolbarColors.Normal
+	rs.ta.ui.FillRectangle(bounds, c.Bg)
+
+	// right aligned in the first line
+	face := rs.ta.ui.FontFace()
+	adv, _ := face.GlyphAdvance('0')
+	x := fixed.I(bounds.Max.X) - adv/2 - font.MeasureString(face, rs.str)
+	pen := fixed.Point26_6{
+		X: x,
+		Y: fixed.I(bounds.Min.Y) + face.Metrics().Ascent,
+	}
+	rs.ta.ui.DrawString(bounds, pen, rs.str, c.Fg)
+}
@@ -9,15 +9,15 @@ import (
 type Separator struct {
 	C     uiutil.Container
 	ui    *UI
-	color color.Color
+	color *color.Color // read at paint time (themes)
 }
 
-func NewSeparator(ui *UI, size int, c color.Color) *Separator {
+func NewSeparator(ui *UI, size int, c *color.Color) *Separator {
 	s := &Separator{ui: ui, color: c}
 	s.C.PaintFunc = s.paint
 	s.C.Style.MainSize = &size
 	return s
 }
 func (s *Separator) paint() {
-	s.ui.FillRectangle(&s.C.Bounds, s.color)
+	s.ui.FillRectangle(&s.C.Bounds, *s.color)
 }
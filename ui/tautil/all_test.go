@@ -1,8 +1,15 @@
 package tautil
 
 import (
-	//"fmt"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"unicode/utf8"
+
+	"github.com/jmigpin/editor/drawutil2/loopers"
+	"github.com/jmigpin/editor/ui/tautil/textbuf"
+	"golang.org/x/image/math/fixed"
 )
 
 type TextaTester struct {
@@ -11,11 +18,37 @@ type TextaTester struct {
 	cursorIndex    int
 	selectionIndex int
 	selectionOn    bool
+	extraCursors   []*Cursor
+	block          *fixed.Rectangle26_6
+	clipboard      string
+	commentStyle   *CommentStyle
+	indentUnit     string
 }
 
 func (ta *TextaTester) Str() string {
 	return ta.str
 }
+func (ta *TextaTester) Len() int {
+	return len(ta.str)
+}
+func (ta *TextaTester) Slice(a, b int) string {
+	return ta.str[a:b]
+}
+func (ta *TextaTester) ReadRuneAt(i int) (rune, int) {
+	return utf8.DecodeRuneInString(ta.str[i:])
+}
+func (ta *TextaTester) ReadLastRuneAt(i int) (rune, int) {
+	return utf8.DecodeLastRuneInString(ta.str[:i])
+}
+func (ta *TextaTester) LineStart(i int) int {
+	return strings.LastIndexByte(ta.str[:i], '\n') + 1
+}
+func (ta *TextaTester) LineEnd(i int) int {
+	if j := strings.IndexByte(ta.str[i:], '\n'); j >= 0 {
+		return i + j
+	}
+	return len(ta.str)
+}
 func (ta *TextaTester) CursorIndex() int {
 	return ta.cursorIndex
 }
@@ -41,17 +74,75 @@ func (ta *TextaTester) SetSelectionIndex(v int) {
 func (ta *TextaTester) SetSelectionOn(v bool) {
 	ta.selectionOn = v
 }
+func (ta *TextaTester) SetSelectionOff() {
+	ta.selectionOn = false
+}
+func (ta *TextaTester) SetSelection(si, ci int) {
+	ta.SetSelectionIndex(si)
+	ta.SetCursorIndex(ci)
+	ta.selectionOn = si != ci
+}
 func (ta *TextaTester) SelectionOn() bool {
 	visible := ta.CursorIndex() != ta.SelectionIndex()
 	return ta.selectionOn && visible
 }
+func (ta *TextaTester) ExtraCursors() []*Cursor {
+	return ta.extraCursors
+}
+func (ta *TextaTester) SetExtraCursors(cs []*Cursor) {
+	ta.extraCursors = cs
+}
+func (ta *TextaTester) SetSelectionBlock(r *fixed.Rectangle26_6) {
+	ta.block = r
+}
+func (ta *TextaTester) IndentUnit() string {
+	return ta.indentUnit
+}
+func (ta *TextaTester) CommentStyle() *CommentStyle {
+	return ta.commentStyle
+}
+func (ta *TextaTester) Folds() []*loopers.SelectionIndexes {
+	return nil
+}
+func (ta *TextaTester) InStringOrComment(int) bool {
+	return false
+}
+func (ta *TextaTester) MakeIndexVisible(int) {
+}
+func (ta *TextaTester) MakeIndexVisibleAtCenter(int) {
+}
+func (ta *TextaTester) SetClipboardCopy(s string) {
+	ta.clipboard = s
+}
+
+// Monospace layout without wrapping: each byte is one unit wide and each line one unit high.
+func (ta *TextaTester) LineHeight() fixed.Int26_6 {
+	return fixed.I(1)
+}
+func (ta *TextaTester) StrHeight() fixed.Int26_6 {
+	return fixed.I(strings.Count(ta.str, "\n") + 1)
+}
+func (ta *TextaTester) PointIndex(p *fixed.Point26_6) int {
+	lines := strings.SplitAfter(ta.str, "\n")
+	i := 0
+	for _, l := range lines[:p.Y.Floor()] {
+		i += len(l)
+	}
+	n := len(strings.TrimSuffix(lines[p.Y.Floor()], "\n"))
+	if c := p.X.Floor(); c < n {
+		n = c
+	}
+	return i + n
+}
 func (ta *TextaTester) EditOpen() {
 }
 func (ta *TextaTester) EditInsert(index int, str string) {
 	ta.str = ta.str[:index] + str + ta.str[index:]
+	ShiftCursorsInsert(ta.extraCursors, index, len(str))
 }
 func (ta *TextaTester) EditDelete(index, index2 int) {
 	ta.str = ta.str[:index] + ta.str[index2:]
+	ShiftCursorsDelete(ta.extraCursors, index, index2)
 }
 func (ta *TextaTester) EditClose() {
 	ta.SetCursorIndex(ta.CursorIndex())
@@ -68,8 +159,9 @@ func TestMoveCursorJump0(t *testing.T) {
 	if !(ta.CursorIndex() == 4) {
 		t.Fatal(ta.CursorIndex())
 	}
+	// end of the next word
 	MoveCursorJumpRight(ta, false)
-	if !(ta.CursorIndex() == 6) {
+	if !(ta.CursorIndex() == 10) {
 		t.Fatal(ta.CursorIndex())
 	}
 }
@@ -85,15 +177,93 @@ func TestMoveCursorJump1(t *testing.T) {
 		t.Fatal("t1", ta.CursorIndex(), ta.SelectionIndex())
 	}
 	MoveCursorJumpRight(ta, false)
-	if !(ta.CursorIndex() == 7 && ta.SelectionIndex() == 3) {
+	if !(ta.CursorIndex() == 12 && ta.SelectionIndex() == 3) {
 		t.Fatal("t2", ta.CursorIndex(), ta.SelectionIndex())
 	}
+	// no more words
 	MoveCursorJumpRight(ta, false)
-	if !(ta.CursorIndex() == 12 && ta.SelectionIndex() == 3) {
+	if !(ta.CursorIndex() == 13 && ta.SelectionIndex() == 3) {
 		t.Fatal("t3", ta.CursorIndex(), ta.SelectionIndex())
 	}
 }
 
+func TestEditHistoryUndoRedo(t *testing.T) {
+	buf := textbuf.NewBuffer("abc")
+	h := NewEditHistory()
+
+	edit := NewEditHistoryEdit(buf)
+	edit.Insert(3, "def")
+	edit.Delete(0, 1)
+	strEdit, ok := edit.Close()
+	if !ok {
+		t.Fatal("expecting changes")
+	}
+	h.PushEdit(strEdit)
+	if buf.String() != "bcdef" {
+		t.Fatal(buf.String())
+	}
+
+	if _, ok := h.PopUndo(buf); !ok || buf.String() != "abc" {
+		t.Fatal(ok, buf.String())
+	}
+	if _, ok := h.UnpopRedo(buf); !ok || buf.String() != "bcdef" {
+		t.Fatal(ok, buf.String())
+	}
+}
+func TestEditHistoryBranches(t *testing.T) {
+	buf := textbuf.NewBuffer("")
+	h := NewEditHistory()
+	insert := func(index int, s string) {
+		edit := NewEditHistoryEdit(buf)
+		edit.Insert(index, s)
+		strEdit, _ := edit.Close()
+		h.PushEdit(strEdit)
+	}
+
+	insert(0, "a ")
+	insert(2, "b ")
+	h.PopUndo(buf)
+	insert(2, "c ") // new branch
+	if buf.String() != "a c " {
+		t.Fatal(buf.String())
+	}
+
+	// go back and redo the first branch
+	h.PopUndo(buf)
+	if i, n := h.SelectRedoBranch(1); i != 0 || n != 2 {
+		t.Fatal(i, n)
+	}
+	h.UnpopRedo(buf)
+	if buf.String() != "a b " {
+		t.Fatal(buf.String())
+	}
+
+	// encode/decode keeps the branches and the position
+	b, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2 := &EditHistory{}
+	if err := json.Unmarshal(b, h2); err != nil {
+		t.Fatal(err)
+	}
+	h2.PopUndo(buf)
+	h2.SelectRedoBranch(1)
+	h2.UnpopRedo(buf)
+	if buf.String() != "a c " {
+		t.Fatal(buf.String())
+	}
+}
+func TestEditHistoryEditNoOp(t *testing.T) {
+	buf := textbuf.NewBuffer("abc")
+	edit := NewEditHistoryEdit(buf)
+	edit.Delete(0, 3)
+	edit.Insert(0, "abc")
+	if _, ok := edit.Close(); ok {
+		t.Fatal("expecting no changes")
+	}
+}
+
 //func testTabLeft(t *testing.T, str1 string, ci1, si1 int, sOn bool, str2 string, ci2, si2 int) {
 //ta := &TextaTester{
 //str:            str1,
@@ -140,3 +310,376 @@ func TestMoveCursorJump1(t *testing.T) {
 //s2 := "\nabcd"
 //testTabLeft(t, s1, 2, 1, true, s2, 1, 0)
 //}
+
+func testCursorIndexes(ta *TextaTester) []int {
+	u := []int{ta.CursorIndex()}
+	for _, c := range ta.ExtraCursors() {
+		u = append(u, c.Index)
+	}
+	return u
+}
+
+func TestMultiCursorInsert(t *testing.T) {
+	ta := &TextaTester{
+		str:          "abc\nabc\nabc",
+		cursorIndex:  4,
+		extraCursors: []*Cursor{{Index: 0}, {Index: 8}},
+	}
+	InsertString(ta, "x")
+	if ta.Str() != "xabc\nxabc\nxabc" {
+		t.Fatal(ta.Str())
+	}
+	if u := testCursorIndexes(ta); fmt.Sprint(u) != "[6 1 11]" {
+		t.Fatal(u)
+	}
+	Backspace(ta)
+	Backspace(ta)
+	if ta.Str() != "abcabcabc" {
+		t.Fatal(ta.Str())
+	}
+	if u := testCursorIndexes(ta); fmt.Sprint(u) != "[3 0 6]" {
+		t.Fatal(u)
+	}
+}
+func TestMultiCursorSelections(t *testing.T) {
+	ta := &TextaTester{str: "ab ab ab"}
+	ta.SetSelection(0, 2)
+	AddCursorNextMatch(ta)
+	AddCursorNextMatch(ta)
+	AddCursorNextMatch(ta) // no more matches
+	if len(ta.ExtraCursors()) != 2 {
+		t.Fatal(len(ta.ExtraCursors()))
+	}
+	InsertString(ta, "cd")
+	if ta.Str() != "cd cd cd" {
+		t.Fatal(ta.Str())
+	}
+}
+func TestMultiCursorLines(t *testing.T) {
+	ta := &TextaTester{str: "a\nb\nc"}
+	ta.SetSelection(0, 5)
+	AddCursorsToLines(ta)
+	if u := testCursorIndexes(ta); fmt.Sprint(u) != "[5 1 3]" {
+		t.Fatal(u)
+	}
+	// cursors on the same line comment the line once
+	ta.extraCursors = append(ta.extraCursors, &Cursor{Index: 0})
+	Comment(ta)
+	if ta.Str() != "//a\n//b\n//c" {
+		t.Fatal(ta.Str())
+	}
+}
+func TestSelectBlock(t *testing.T) {
+	ta := &TextaTester{str: "abcd\nef\nghij"}
+	SelectBlock(ta, &fixed.Point26_6{fixed.I(1), 0}, &fixed.Point26_6{fixed.I(3), fixed.I(2)})
+	if ta.block == nil || ta.block.Max.Y != fixed.I(3) {
+		t.Fatal(ta.block)
+	}
+	Copy(ta)
+	if ta.clipboard != "bc\nf\nhi" {
+		t.Fatalf("%q", ta.clipboard)
+	}
+	// one line per cursor
+	InsertString(ta, "X\nY\nZ\n")
+	if ta.Str() != "aXd\neY\ngZj" {
+		t.Fatalf("%q", ta.Str())
+	}
+	Backspace(ta)
+	if ta.Str() != "ad\ne\ngj" {
+		t.Fatalf("%q", ta.Str())
+	}
+}
+func TestSelectBlockShortLines(t *testing.T) {
+	// short and empty middle lines copy as empty lines
+	ta := &TextaTester{str: "abcd\na\n\nefgh"}
+	SelectBlock(ta, &fixed.Point26_6{fixed.I(2), 0}, &fixed.Point26_6{fixed.I(4), fixed.I(3)})
+	Cut(ta)
+	if ta.clipboard != "cd\n\n\ngh" || ta.Str() != "ab\na\n\nef" {
+		t.Fatalf("%q %q", ta.clipboard, ta.Str())
+	}
+	// pasted back one line per cursor
+	InsertString(ta, ta.clipboard)
+	if ta.Str() != "abcd\na\n\nefgh" {
+		t.Fatalf("%q", ta.Str())
+	}
+}
+
+func TestFindRegexp(t *testing.T) {
+	ta := &TextaTester{str: "Foo foobar foo"}
+	opt := &FindOpt{WholeWord: true, CaseSensitive: true}
+	if err := Find(ta, "foo", opt); err != nil {
+		t.Fatal(err)
+	}
+	if !(ta.SelectionIndex() == 11 && ta.CursorIndex() == 14) {
+		t.Fatal(ta.SelectionIndex(), ta.CursorIndex())
+	}
+	// wraps around
+	opt = &FindOpt{Regexp: true}
+	if err := Find(ta, "f(o+)", opt); err != nil {
+		t.Fatal(err)
+	}
+	if !(ta.SelectionIndex() == 0 && ta.CursorIndex() == 3) {
+		t.Fatal(ta.SelectionIndex(), ta.CursorIndex())
+	}
+	if err := Find(ta, "f(", opt); err == nil {
+		t.Fatal("expecting error")
+	}
+}
+func TestReplaceRegexp(t *testing.T) {
+	ta := &TextaTester{str: "a=1 b=2 c=3"}
+	opt := &FindOpt{Regexp: true}
+	n, err := Replace(ta, `(?P<k>\w)=(\d)`, "$2=${k}", opt)
+	if err != nil || n != 3 || ta.Str() != "1=a 2=b 3=c" {
+		t.Fatal(n, err, ta.Str())
+	}
+	// literal replacement, limited to the selection
+	ta.SetSelection(4, 11)
+	n, err = Replace(ta, "=", "$1", &FindOpt{})
+	if err != nil || n != 2 || ta.Str() != "1=a 2$1b 3$1c" {
+		t.Fatal(n, err, ta.Str())
+	}
+}
+func TestFindRegexpFromIndex(t *testing.T) {
+	ta := &TextaTester{str: "ab ab ab"}
+	re, _ := (&FindOpt{}).Compile("ab")
+	if !FindRegexpFromIndex(ta, re, 3, true) || ta.SelectionIndex() != 0 {
+		t.Fatal(ta.SelectionIndex())
+	}
+	// wraps around to the last match
+	if !FindRegexpFromIndex(ta, re, 0, true) || ta.SelectionIndex() != 6 {
+		t.Fatal(ta.SelectionIndex())
+	}
+	if !FindRegexpFromIndex(ta, re, 4, false) || ta.SelectionIndex() != 6 {
+		t.Fatal(ta.SelectionIndex())
+	}
+}
+func TestFindRegexpAnchors(t *testing.T) {
+	// the search starts at the cursor line start: no "^" or "\b" matches in the middle of the line
+	str := "xab ab\nab" + strings.Repeat(" ", 10000) + "ab"
+	ta := &TextaTester{str: str}
+	re, _ := (&FindOpt{Regexp: true, WholeWord: true}).Compile("^ab")
+	if !FindRegexpFromIndex(ta, re, 2, false) || ta.SelectionIndex() != 7 {
+		t.Fatal(ta.SelectionIndex())
+	}
+	re, _ = (&FindOpt{WholeWord: true}).Compile("ab")
+	if !FindRegexpFromIndex(ta, re, 1, false) || ta.SelectionIndex() != 4 {
+		t.Fatal(ta.SelectionIndex())
+	}
+	// previous match farther than the first backward window
+	if !FindRegexpFromIndex(ta, re, len(str)-2, true) || ta.SelectionIndex() != 7 {
+		t.Fatal(ta.SelectionIndex())
+	}
+	if !FindRegexpFromIndex(ta, re, 4, true) || ta.SelectionIndex() != len(str)-2 {
+		t.Fatal(ta.SelectionIndex())
+	}
+}
+
+func TestMatchingBracket(t *testing.T) {
+	ta := &TextaTester{str: "f(a[1], {b})"}
+	ta.cursorIndex = 1
+	if i, j, ok := MatchingBracket(ta); !ok || i != 1 || j != 11 {
+		t.Fatal(i, j, ok)
+	}
+	// after the closing bracket
+	ta.cursorIndex = 12
+	if i, j, ok := MatchingBracket(ta); !ok || i != 11 || j != 1 {
+		t.Fatal(i, j, ok)
+	}
+	JumpToMatchingBracket(ta)
+	if ta.CursorIndex() != 2 {
+		t.Fatal(ta.CursorIndex())
+	}
+}
+
+func TestSelectBetweenBrackets(t *testing.T) {
+	ta := &TextaTester{str: "f(a[1], {b})"}
+	ta.cursorIndex = 7 // enclosing brackets
+	SelectBetweenBrackets(ta)
+	a, b := SelectionStringIndexes(ta)
+	if ta.Str()[a:b] != "a[1], {b}" {
+		t.Fatal(ta.Str()[a:b])
+	}
+}
+
+func TestMatchBracketSkip(t *testing.T) {
+	r := newTextReader(&TextaTester{str: `f(")", a)`})
+	skip := func(i int) bool { return i >= 2 && i <= 4 } // string
+	if j, ok := matchBracket(r, 1, skip); !ok || j != 8 {
+		t.Fatal(j, ok)
+	}
+}
+
+func TestMatchBracketChunks(t *testing.T) {
+	// brackets farther apart than the reader chunk
+	str := "{" + strings.Repeat("(a)\n", 3000) + "}"
+	r := newTextReader(&TextaTester{str: str})
+	noSkip := func(int) bool { return false }
+	if j, ok := matchBracket(r, 0, noSkip); !ok || j != len(str)-1 {
+		t.Fatal(j, ok)
+	}
+	if j, ok := matchBracket(r, len(str)-1, noSkip); !ok || j != 0 {
+		t.Fatal(j, ok)
+	}
+	if i, ok := enclosingBracket(r, len(str)-1, noSkip); !ok || i != 0 {
+		t.Fatal(i, ok)
+	}
+}
+
+func TestFoldRange(t *testing.T) {
+	str := "import (\n\t\"a\"\n\t\"b\"\n)\n\nfunc f() {\n\tx := T{\n\t\t1,\n\t}\n}\n"
+	ta := &TextaTester{str: str}
+	u := FoldAllRanges(ta)
+	if len(u) != 2 {
+		t.Fatal(u)
+	}
+	if s := str[u[0][0]:u[0][1]]; s != "\t\"a\"\n\t\"b\"\n" {
+		t.Fatalf("%q", s)
+	}
+	if s := str[u[1][0]:u[1][1]]; s != "\tx := T{\n\t\t1,\n\t}\n" {
+		t.Fatalf("%q", s)
+	}
+
+	// composite literal at the cursor line
+	a, b, ok := FoldRange(ta, strings.Index(str, "T{"))
+	if !ok || str[a:b] != "\t\t1,\n" {
+		t.Fatalf("%q", str[a:b])
+	}
+
+	// by indentation
+	str = "def f():\n    a\n\n    b\nc\n"
+	ta = &TextaTester{str: str}
+	a, b, ok = FoldRange(ta, 0)
+	if !ok || str[a:b] != "    a\n\n    b\n" {
+		t.Fatalf("%q", str[a:b])
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	str := "fooBar fooBaz foo\nfooBaz x := fo"
+	i, p := WordPrefix(&TextaTester{str: str}, len(str))
+	if i != len(str)-2 || p != "fo" {
+		t.Fatal(i, p)
+	}
+	// closest first, then by frequency
+	u := CompletionWords(p, str, len(str), []string{"fooQux fooQux fooZ"})
+	w := []string{"fooBaz", "foo", "fooBar", "fooQux", "fooZ"}
+	if strings.Join(u, " ") != strings.Join(w, " ") {
+		t.Fatal(u)
+	}
+
+	ta := &TextaTester{str: str, cursorIndex: len(str)}
+	InsertCompletion(ta, i, u[0])
+	if ta.str != "fooBar fooBaz foo\nfooBaz x := fooBaz" || ta.cursorIndex != len(ta.str) {
+		t.Fatal(ta.str, ta.cursorIndex)
+	}
+}
+
+func TestCommentStyles(t *testing.T) {
+	ta := &TextaTester{str: "\tif a\n\t\tb\n", commentStyle: &CommentStyle{Line: "#"}}
+	ta.SetSelection(0, 8)
+	Comment(ta)
+	if ta.Str() != "\t#if a\n\t#\tb\n" {
+		t.Fatalf("%q", ta.Str())
+	}
+	Uncomment(ta)
+	if ta.Str() != "\tif a\n\t\tb\n" {
+		t.Fatalf("%q", ta.Str())
+	}
+
+	// block comment, cursor keeps its position in the text
+	ta = &TextaTester{str: "  a {b}\n", cursorIndex: 4, commentStyle: &CommentStyle{Block: [2]string{"/*", "*/"}}}
+	Comment(ta)
+	if ta.Str() != "  /* a {b} */\n" || ta.Str()[ta.cursorIndex:] != "{b} */\n" {
+		t.Fatalf("%q %v", ta.Str(), ta.cursorIndex)
+	}
+	Uncomment(ta)
+	if ta.Str() != "  a {b}\n" || ta.cursorIndex != 4 {
+		t.Fatalf("%q %v", ta.Str(), ta.cursorIndex)
+	}
+
+	// multiple lines in one block
+	ta = &TextaTester{str: "<a>\n<b>\n", commentStyle: &CommentStyle{Block: [2]string{"<!--", "-->"}}}
+	ta.SetSelection(0, 8)
+	Comment(ta)
+	if ta.Str() != "<!-- <a>\n<b> -->\n" {
+		t.Fatalf("%q", ta.Str())
+	}
+	Uncomment(ta)
+	if ta.Str() != "<a>\n<b>\n" {
+		t.Fatalf("%q", ta.Str())
+	}
+}
+
+func TestDetectIndent(t *testing.T) {
+	if u, ok := DetectIndent("a\n\tb\n\t\tc\n  d\n"); !ok || u != "\t" {
+		t.Fatalf("%q", u)
+	}
+	if u, ok := DetectIndent("a:\n    b\n        c\n    d\n/*\n * e\n */\n"); !ok || u != "    " {
+		t.Fatalf("%q", u)
+	}
+	if u, ok := DetectIndent("a\n  b\n    c\n"); !ok || u != "  " {
+		t.Fatalf("%q", u)
+	}
+	if _, ok := DetectIndent("a\nb\n"); ok {
+		t.Fatal("expecting no indentation")
+	}
+}
+func TestAutoIndentBrackets(t *testing.T) {
+	// one level added after the opener, closer on its own line
+	ta := &TextaTester{str: "  f(a, func() {})"}
+	ta.cursorIndex = strings.Index(ta.str, "{") + 1
+	AutoIndent(ta)
+	if ta.str != "  f(a, func() {\n    \n  })" || ta.cursorIndex != 20 {
+		t.Fatalf("%q %v", ta.str, ta.cursorIndex)
+	}
+
+	// detected unit
+	ta = &TextaTester{str: "func f() {\n\tif a {", cursorIndex: 18}
+	AutoIndent(ta)
+	if ta.str != "func f() {\n\tif a {\n\t\t" {
+		t.Fatalf("%q", ta.str)
+	}
+	// closer dedents to the opener line indentation
+	InsertClosingBracket(ta, '}')
+	if ta.str != "func f() {\n\tif a {\n\t}" || ta.cursorIndex != len(ta.str) {
+		t.Fatalf("%q %v", ta.str, ta.cursorIndex)
+	}
+	// not at the line start
+	InsertClosingBracket(ta, ')')
+	if ta.str != "func f() {\n\tif a {\n\t})" {
+		t.Fatalf("%q", ta.str)
+	}
+}
+func TestIndentUnitTabs(t *testing.T) {
+	ta := &TextaTester{str: "a\nb\n", indentUnit: "  "}
+	ta.SetSelection(0, 3)
+	TabRight(ta)
+	if ta.str != "  a\n  b\n" {
+		t.Fatalf("%q", ta.str)
+	}
+	ta.str = "   a\n\tb\n"
+	ta.SetSelection(0, 7)
+	TabLeft(ta)
+	if ta.str != " a\nb\n" {
+		t.Fatalf("%q", ta.str)
+	}
+}
+func TestReindent(t *testing.T) {
+	ta := &TextaTester{str: "a\n    b\n\t  c\n        d", cursorIndex: 9}
+	IndentToTabs(ta, 4)
+	if ta.str != "a\n\tb\n\t  c\n\t\td" || ta.str[ta.cursorIndex:] != "c\n\t\td" {
+		t.Fatalf("%q %v", ta.str, ta.cursorIndex)
+	}
+	IndentToSpaces(ta, 2)
+	if ta.str != "a\n  b\n    c\n    d" {
+		t.Fatalf("%q", ta.str)
+	}
+
+	// selection lines only
+	ta = &TextaTester{str: "\ta\n\tb\n\tc\n"}
+	ta.SetSelection(3, 4)
+	IndentToSpaces(ta, 4)
+	if ta.str != "\ta\n    b\n\tc\n" {
+		t.Fatalf("%q", ta.str)
+	}
+}
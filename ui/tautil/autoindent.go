@@ -1,18 +1,15 @@
 package tautil
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
+// Inserts a newline keeping the line indentation. An opening bracket before the cursor adds one indentation level, and a matching closing bracket after the cursor goes to its own line.
 func AutoIndent(ta Texta) {
-	// string to insert
-	ci := ta.CursorIndex()
-	k := lineStartIndex(ta.Str(), ci)
-	j := strings.IndexFunc(ta.Str()[k:ci], isNotSpace)
-	if j < 0 {
-		// full line of spaces, indent to cursor position
-		j = ci - k
-	}
-	str := "\n" + ta.Str()[k:k+j]
-
+	forEachCursor(ta, func() { autoIndent(ta) })
+}
+func autoIndent(ta Texta) {
 	ta.EditOpen()
 	if ta.SelectionOn() {
 		// remove selection
@@ -21,8 +18,76 @@ func AutoIndent(ta Texta) {
 		ta.SetSelectionOff()
 		ta.SetCursorIndex(a)
 	}
+
+	// string to insert
+	ci := ta.CursorIndex()
+	k := ta.LineStart(ci)
+	before := ta.Slice(k, ci)
+	j := strings.IndexFunc(before, isNotSpace)
+	if j < 0 {
+		// full line of spaces, indent to cursor position
+		j = ci - k
+	}
+	indent := before[:j]
+	s := "\n" + indent
+	after := ""
+
+	if o, ok := openerBeforeIndex(ta, before, k); ok {
+		s += indentUnit(ta)
+		// closer goes to its own line
+		rest := ta.Slice(ci, ta.LineEnd(ci))
+		w := len(rest) - len(strings.TrimLeft(rest, " \t"))
+		if w < len(rest) && rest[w] == closingBracket(o) {
+			ta.EditDelete(ci, ci+w)
+			after = "\n" + indent
+		}
+	}
+
 	// insert
-	ta.EditInsert(ta.CursorIndex(), str)
+	ta.EditInsert(ci, s+after)
+	ta.EditClose()
+	ta.SetCursorIndex(ci + len(s))
+}
+
+// Opening bracket ending the line text before the cursor (before), outside strings and comments.
+func openerBeforeIndex(ta Texta, before string, lineStart int) (byte, bool) {
+	u := strings.TrimRightFunc(before, unicode.IsSpace)
+	if u == "" {
+		return 0, false
+	}
+	i := len(u) - 1
+	switch b := u[i]; b {
+	case '(', '[', '{':
+		if !ta.InStringOrComment(lineStart + i) {
+			return b, true
+		}
+	}
+	return 0, false
+}
+
+// Inserts the closing bracket. On a line with only spaces before the cursor, the line gets the indentation of the line with the opening bracket (or one level less).
+func InsertClosingBracket(ta Texta, b byte) {
+	forEachCursor(ta, func() { insertClosingBracket(ta, b) })
+}
+func insertClosingBracket(ta Texta, b byte) {
+	ci := ta.CursorIndex()
+	k := ta.LineStart(ci)
+	if ta.SelectionOn() || ci == k || strings.TrimLeft(ta.Slice(k, ci), " \t") != "" {
+		insertString(ta, string(b))
+		return
+	}
+
+	indent := ta.Slice(k, ci)
+	r := newTextReader(ta)
+	if i, ok := enclosingBracket(r, k, ta.InStringOrComment); ok && r.byteAt(i) == openingBracket(b) {
+		indent = lineIndent(ta, i)
+	} else {
+		indent = strings.TrimSuffix(indent, indentUnit(ta))
+	}
+
+	ta.EditOpen()
+	ta.EditDelete(k, ci)
+	ta.EditInsert(k, indent+string(b))
 	ta.EditClose()
-	ta.SetCursorIndex(ta.CursorIndex() + len(str))
+	ta.SetCursorIndex(k + len(indent) + 1)
 }
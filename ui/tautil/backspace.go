@@ -1,6 +1,9 @@
 package tautil
 
 func Backspace(ta Texta) {
+	forEachCursor(ta, func() { backspace(ta) })
+}
+func backspace(ta Texta) {
 	var a, b int
 	var ok bool
 	if ta.SelectionOn() {
@@ -8,7 +11,7 @@ func Backspace(ta Texta) {
 		ta.SetSelectionOff()
 	} else {
 		b = ta.CursorIndex()
-		_, a, ok = PreviousRuneIndex(ta.Str(), b)
+		_, a, ok = PreviousRuneIndex(ta, b)
 		if !ok {
 			return
 		}
@@ -0,0 +1,139 @@
+package tautil
+
+// Bracket at the cursor (or before the cursor) and its matching bracket. Brackets inside strings and comments are skipped.
+func MatchingBracket(ta Texta) (int, int, bool) {
+	r := newTextReader(ta)
+	ci := ta.CursorIndex()
+	for _, i := range []int{ci, ci - 1} {
+		if i < 0 || i >= r.n || ta.InStringOrComment(i) {
+			continue
+		}
+		if j, ok := matchBracket(r, i, ta.InStringOrComment); ok {
+			return i, j, true
+		}
+	}
+	return 0, 0, false
+}
+
+// Moves the cursor to the matching bracket, keeping the cursor side of the bracket.
+func JumpToMatchingBracket(ta Texta) {
+	forEachCursor(ta, func() {
+		jumpToMatchingBracket(ta)
+	})
+}
+func jumpToMatchingBracket(ta Texta) {
+	i, j, ok := MatchingBracket(ta)
+	if !ok {
+		return
+	}
+	if i < ta.CursorIndex() {
+		j++ // cursor was after the bracket
+	}
+	ta.SetSelectionOff()
+	ta.SetCursorIndex(j)
+}
+
+// Selects the text between the bracket at the cursor and its match. Without a bracket at the cursor, uses the enclosing brackets.
+func SelectBetweenBrackets(ta Texta) {
+	forEachCursor(ta, func() {
+		selectBetweenBrackets(ta)
+	})
+}
+func selectBetweenBrackets(ta Texta) {
+	i, j, ok := MatchingBracket(ta)
+	if !ok {
+		r := newTextReader(ta)
+		i, ok = enclosingBracket(r, ta.CursorIndex(), ta.InStringOrComment)
+		if !ok {
+			return
+		}
+		j, ok = matchBracket(r, i, ta.InStringOrComment)
+		if !ok {
+			return
+		}
+	}
+	if i > j {
+		i, j = j, i
+	}
+	ta.SetSelection(i+1, j)
+}
+
+// Index of the bracket matching the one at index i.
+func matchBracket(r *textReader, i int, skip func(int) bool) (int, bool) {
+	c := r.byteAt(i)
+	// ob is the bracket at i, that increases the depth
+	var ob, cb byte
+	dir := 1
+	switch c {
+	case '(', '[', '{':
+		ob, cb = c, closingBracket(c)
+	case ')', ']', '}':
+		ob, cb = c, openingBracket(c)
+		dir = -1
+	default:
+		return 0, false
+	}
+
+	depth := 0
+	for k, n := i, 0; k >= 0 && k < r.n && n < bracketsMaxDistance; k, n = k+dir, n+1 {
+		// brackets are ascii, no need to decode runes
+		b := r.byteAt(k)
+		if (b != ob && b != cb) || skip(k) {
+			continue
+		}
+		if b == ob {
+			depth++
+		} else {
+			depth--
+		}
+		if depth == 0 {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// Opening bracket before the index that is not closed before the index.
+func enclosingBracket(r *textReader, index int, skip func(int) bool) (int, bool) {
+	depth := map[byte]int{}
+	for k, n := index-1, 0; k >= 0 && n < bracketsMaxDistance; k, n = k-1, n+1 {
+		b := r.byteAt(k)
+		switch b {
+		case ')', ']', '}':
+			if !skip(k) {
+				depth[openingBracket(b)]++
+			}
+		case '(', '[', '{':
+			if skip(k) {
+				continue
+			}
+			if depth[b] == 0 {
+				return k, true
+			}
+			depth[b]--
+		}
+	}
+	return 0, false
+}
+
+func closingBracket(b byte) byte {
+	switch b {
+	case '(':
+		return ')'
+	case '[':
+		return ']'
+	}
+	return '}'
+}
+func openingBracket(b byte) byte {
+	switch b {
+	case ')':
+		return '('
+	case ']':
+		return '['
+	}
+	return '{'
+}
+
+// Limits the search in big texts.
+var bracketsMaxDistance = 200000
@@ -3,12 +3,36 @@ package tautil
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// Line comment prefix, or block comment start and end if there is no line prefix.
+type CommentStyle struct {
+	Line  string
+	Block [2]string
+}
+
+var DefaultCommentStyle = &CommentStyle{Line: "//"}
+
+func commentStyle(ta Texta) *CommentStyle {
+	if cs := ta.CommentStyle(); cs != nil {
+		return cs
+	}
+	return DefaultCommentStyle
+}
+
 func Comment(ta Texta) {
+	cs := commentStyle(ta)
+	if cs.Line == "" {
+		forEachCursorLines(ta, false, func() { blockComment(ta, cs.Block) })
+		return
+	}
+	forEachCursorLines(ta, false, func() { comment(ta, cs.Line) })
+}
+func comment(ta Texta, prefix string) {
 	a, b, _ := linesStringIndexes(ta)
 
-	str := ta.Str()[a:b]
+	str := ta.Slice(a, b)
 
 	IsNotASpaceExceptNewLine := func(ru rune) bool {
 		return !unicode.IsSpace(ru) || ru == '\n'
@@ -43,7 +67,7 @@ func Comment(ta Texta) {
 		i += start
 
 		// insert comment
-		str = str[:i] + "//" + str[i:]
+		str = str[:i] + prefix + str[i:]
 		altered = true
 
 		nlines++
@@ -62,11 +86,11 @@ func Comment(ta Texta) {
 	if nlines <= 1 {
 		ta.SetSelectionOff()
 		// move cursor to the right due to inserted runes
-		i := strings.Index(ta.Str()[a:a+len(str)], "//")
+		i := strings.Index(ta.Slice(a, a+len(str)), prefix)
 		if i >= 0 {
 			ci := ta.CursorIndex()
 			if ci >= a+i {
-				ta.SetCursorIndex(ci + 2)
+				ta.SetCursorIndex(ci + len(prefix))
 			}
 		}
 	} else {
@@ -74,9 +98,17 @@ func Comment(ta Texta) {
 	}
 }
 func Uncomment(ta Texta) {
+	cs := commentStyle(ta)
+	if cs.Line == "" {
+		forEachCursorLines(ta, false, func() { blockUncomment(ta, cs.Block) })
+		return
+	}
+	forEachCursorLines(ta, false, func() { uncomment(ta, cs.Line) })
+}
+func uncomment(ta Texta, prefix string) {
 	a, b, _ := linesStringIndexes(ta)
 
-	str := ta.Str()[a:b]
+	str := ta.Slice(a, b)
 	altered := false
 	nlines := 0
 	for i := 0; i < len(str); i, _ = lineEndIndexNextIndex(str, i) {
@@ -88,9 +120,9 @@ func Uncomment(ta Texta) {
 		i += j
 
 		// remove comment
-		if strings.HasPrefix(str[i:], "//") {
+		if strings.HasPrefix(str[i:], prefix) {
 			altered = true
-			str = str[:i] + str[i+len("//"):]
+			str = str[:i] + str[i+len(prefix):]
 		}
 
 		nlines++
@@ -109,14 +141,105 @@ func Uncomment(ta Texta) {
 	if nlines <= 1 {
 		ta.SetSelectionOff()
 		// move cursor to the left due to deleted runes
-		i := strings.IndexFunc(ta.Str()[a:a+len(str)], isNotSpace)
+		i := strings.IndexFunc(ta.Slice(a, a+len(str)), isNotSpace)
 		if i >= 0 {
 			ci := ta.CursorIndex()
 			if ci > a+i {
-				ta.SetCursorIndex(ci - 2)
+				ta.SetCursorIndex(ci - len(prefix))
 			}
 		}
 	} else {
 		ta.SetSelection(a, a+len(str)-1)
 	}
 }
+
+// Surrounds the lines text (without the leading and trailing spaces) with the block comment.
+func blockComment(ta Texta, block [2]string) {
+	a, b, _ := linesStringIndexes(ta)
+	str := ta.Slice(a, b)
+	i, j, ok := trimmedIndexes(str)
+	if !ok {
+		return
+	}
+	start, end := block[0]+" ", " "+block[1]
+	str2 := str[:i] + start + str[i:j] + end + str[j:]
+
+	ta.EditOpen()
+	ta.EditDelete(a, b)
+	ta.EditInsert(a, str2)
+	ta.EditClose()
+
+	if strings.Count(str[:j], "\n") == 0 {
+		ta.SetSelectionOff()
+		// move cursor to the right due to inserted runes
+		ci := ta.CursorIndex()
+		if ci >= a+j {
+			ci += len(end)
+		}
+		if ci >= a+i {
+			ci += len(start)
+		}
+		ta.SetCursorIndex(ci)
+	} else {
+		ta.SetSelection(a, a+len(str2)-1)
+	}
+}
+func blockUncomment(ta Texta, block [2]string) {
+	a, b, _ := linesStringIndexes(ta)
+	str := ta.Slice(a, b)
+	i, j, ok := trimmedIndexes(str)
+	if !ok {
+		return
+	}
+	u := str[i:j]
+	if len(u) < len(block[0])+len(block[1]) ||
+		!strings.HasPrefix(u, block[0]) ||
+		!strings.HasSuffix(u, block[1]) {
+		return
+	}
+	// comment start and end with the optional inner spaces
+	i2 := i + len(block[0])
+	j2 := j - len(block[1])
+	if i2 < j2 && str[i2] == ' ' {
+		i2++
+	}
+	if i2 < j2 && str[j2-1] == ' ' {
+		j2--
+	}
+	str2 := str[:i] + str[i2:j2] + str[j:]
+
+	ta.EditOpen()
+	ta.EditDelete(a, b)
+	ta.EditInsert(a, str2)
+	ta.EditClose()
+
+	if strings.Count(str[:j], "\n") == 0 {
+		ta.SetSelectionOff()
+		// move cursor to the left due to deleted runes
+		ci := ta.CursorIndex() - a
+		switch {
+		case ci >= j:
+			ci -= (i2 - i) + (j - j2)
+		case ci >= j2:
+			ci = j2 - (i2 - i)
+		case ci >= i2:
+			ci -= i2 - i
+		case ci > i:
+			ci = i
+		}
+		ta.SetCursorIndex(a + ci)
+	} else {
+		ta.SetSelection(a, a+len(str2)-1)
+	}
+}
+
+// Indexes of the string without the leading and trailing spaces.
+func trimmedIndexes(str string) (int, int, bool) {
+	i := strings.IndexFunc(str, isNotSpace)
+	if i < 0 {
+		return 0, 0, false
+	}
+	j := strings.LastIndexFunc(str, isNotSpace)
+	_, size := utf8.DecodeRuneInString(str[j:])
+	return i, j + size, true
+}
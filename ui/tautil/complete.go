@@ -0,0 +1,110 @@
+package tautil
+
+import (
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// Word before the index (word runes only) and its start index.
+func WordPrefix(ta Texta, index int) (int, string) {
+	i := lastIndexFunc(ta, index, func(ru rune) bool {
+		return !isWordRune(ru)
+	})
+	if i < 0 {
+		i = 0
+	} else {
+		_, size := ta.ReadRuneAt(i)
+		i += size
+	}
+	return i, ta.Slice(i, index)
+}
+
+// Words starting with the prefix (longer than the prefix), from the string being edited and from other strings. Words closer to the index rank first, the distance measured in orders of magnitude so that frequent words can rank above slightly closer ones. Words only in the other strings rank by frequency after the words of the edited string.
+func CompletionWords(prefix, str string, index int, others []string) []string {
+	type cand struct {
+		dist  int // -1 if not in str
+		count int
+	}
+	cands := map[string]*cand{}
+	add := func(s string, near bool) {
+		start := -1
+		for i, ru := range s + " " {
+			if isWordRune(ru) {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+			if start < 0 {
+				continue
+			}
+			w := s[start:i]
+			wstart := start
+			start = -1
+			if len(w) <= len(prefix) || !strings.HasPrefix(w, prefix) {
+				continue
+			}
+			// word being completed
+			if near && wstart == index-len(prefix) {
+				continue
+			}
+			c, ok := cands[w]
+			if !ok {
+				c = &cand{dist: -1}
+				cands[w] = c
+			}
+			c.count++
+			if near {
+				d := wstart - index
+				if d < 0 {
+					d = -d
+				}
+				if c.dist < 0 || d < c.dist {
+					c.dist = d
+				}
+			}
+		}
+	}
+	add(str, true)
+	for _, s := range others {
+		add(s, false)
+	}
+
+	rank := func(c *cand) int {
+		if c.dist < 0 {
+			return 64
+		}
+		return bits.Len(uint(c.dist))
+	}
+	var u []string
+	for w := range cands {
+		u = append(u, w)
+	}
+	sort.Slice(u, func(i, j int) bool {
+		ci, cj := cands[u[i]], cands[u[j]]
+		ri, rj := rank(ci), rank(cj)
+		if ri != rj {
+			return ri < rj
+		}
+		if ci.count != cj.count {
+			return ci.count > cj.count
+		}
+		return u[i] < u[j]
+	})
+	return u
+}
+
+// Replaces the word before the cursor, starting at index, with the completion.
+func InsertCompletion(ta Texta, index int, word string) {
+	ci := ta.CursorIndex()
+	if index > ci {
+		return
+	}
+	ta.EditOpen()
+	ta.EditDelete(index, ci)
+	ta.EditInsert(index, word)
+	ta.EditClose()
+	ta.SetSelectionOff()
+	ta.SetCursorIndex(index + len(word))
+}
@@ -1,10 +1,12 @@
 package tautil
 
+import "strings"
+
+// With multiple cursors (ex: block selection), copies one line per cursor.
 func Copy(ta Texta) {
-	if !ta.SelectionOn() {
+	u := cursorsSelections(ta)
+	if len(u) == 0 {
 		return
 	}
-	a, b := SelectionStringIndexes(ta)
-	s := ta.Str()[a:b]
-	ta.SetClipboardCopy(s)
+	ta.SetClipboardCopy(strings.Join(u, "\n"))
 }
@@ -0,0 +1,231 @@
+package tautil
+
+import (
+	"sort"
+	"strings"
+)
+
+// Extra cursor used for multiple cursors editing. The texta cursor and selection is the primary cursor.
+type Cursor struct {
+	Index    int
+	SelIndex int
+	SelOn    bool
+}
+
+func (c *Cursor) indexes() (int, int) {
+	if !c.SelOn {
+		return c.Index, c.Index
+	}
+	if c.SelIndex < c.Index {
+		return c.SelIndex, c.Index
+	}
+	return c.Index, c.SelIndex
+}
+
+// Keeps the cursors at the same text position after an insert.
+func ShiftCursorsInsert(cs []*Cursor, index, n int) {
+	for _, c := range cs {
+		if c.Index >= index {
+			c.Index += n
+		}
+		if c.SelIndex >= index {
+			c.SelIndex += n
+		}
+	}
+}
+
+// Keeps the cursors at the same text position after a delete.
+func ShiftCursorsDelete(cs []*Cursor, index, index2 int) {
+	shift := func(i int) int {
+		if i >= index2 {
+			return i - (index2 - index)
+		}
+		if i > index {
+			return index
+		}
+		return i
+	}
+	for _, c := range cs {
+		c.Index = shift(c.Index)
+		c.SelIndex = shift(c.SelIndex)
+		if c.Index == c.SelIndex {
+			c.SelOn = false
+		}
+	}
+}
+
+func currentCursor(ta Texta) *Cursor {
+	return &Cursor{
+		Index:    ta.CursorIndex(),
+		SelIndex: ta.SelectionIndex(),
+		SelOn:    ta.SelectionOn(),
+	}
+}
+func setCurrentCursor(ta Texta, c *Cursor) {
+	if c.SelOn {
+		ta.SetSelection(c.SelIndex, c.Index)
+	} else {
+		ta.SetSelectionOff()
+		ta.SetCursorIndex(c.Index)
+	}
+}
+
+// Runs fn once per cursor, with each cursor set as the texta cursor. All the edits are kept in one undo entry.
+func forEachCursor(ta Texta, fn func()) {
+	forEachCursor2(ta, false, false, fn)
+}
+
+// Same as forEachCursor, but cursors sharing lines are merged first. Reverse runs from the last line.
+func forEachCursorLines(ta Texta, reverse bool, fn func()) {
+	forEachCursor2(ta, true, reverse, fn)
+}
+
+func forEachCursor2(ta Texta, lines, reverse bool, fn func()) {
+	extra := ta.ExtraCursors()
+	if len(extra) == 0 {
+		fn()
+		return
+	}
+
+	primary := currentCursor(ta)
+	cs := append([]*Cursor{primary}, extra...)
+	sortCursors(cs)
+	if lines {
+		cs = append(mergeCursorsLines(ta, cs, primary), primary)
+		sortCursors(cs)
+	}
+	if reverse {
+		for i, j := 0, len(cs)-1; i < j; i, j = i+1, j-1 {
+			cs[i], cs[j] = cs[j], cs[i]
+		}
+	}
+
+	ta.EditOpen()
+	for _, c := range cs {
+		// other cursors are kept updated by the texta edits
+		ta.SetExtraCursors(cursorsWithout(cs, c))
+		setCurrentCursor(ta, c)
+		fn()
+		*c = *currentCursor(ta)
+	}
+	ta.EditClose()
+
+	setCurrentCursor(ta, primary)
+	ta.SetExtraCursors(mergeCursors(cs, primary))
+	ta.MakeIndexVisible(primary.Index)
+}
+
+func sortCursors(cs []*Cursor) {
+	sort.Slice(cs, func(i, j int) bool {
+		a, _ := cs[i].indexes()
+		b, _ := cs[j].indexes()
+		return a < b
+	})
+}
+func cursorsWithout(cs []*Cursor, c *Cursor) []*Cursor {
+	var u []*Cursor
+	for _, c2 := range cs {
+		if c2 != c {
+			u = append(u, c2)
+		}
+	}
+	return u
+}
+
+// Removes cursors at the same position or with overlapping selections, keeping the primary. Returns the extra cursors.
+func mergeCursors(cs []*Cursor, primary *Cursor) []*Cursor {
+	sortCursors(cs)
+	return mergeCursorsFn(cs, primary, func(prev, c *Cursor) bool {
+		ps, pe := prev.indexes()
+		s, _ := c.indexes()
+		return s < pe || s == ps
+	})
+}
+
+// Removes cursors sharing lines with the previous cursor, keeping the primary. Returns the extra cursors.
+func mergeCursorsLines(ta Texta, cs []*Cursor, primary *Cursor) []*Cursor {
+	return mergeCursorsFn(cs, primary, func(prev, c *Cursor) bool {
+		_, pe := prev.indexes()
+		s, _ := c.indexes()
+		e, hasNewline := lineEndNextIndex(ta, pe)
+		return s < e || (s == e && !hasNewline)
+	})
+}
+
+func mergeCursorsFn(cs []*Cursor, primary *Cursor, overlap func(prev, c *Cursor) bool) []*Cursor {
+	var u []*Cursor
+	for _, c := range cs {
+		if len(u) > 0 {
+			prev := u[len(u)-1]
+			if overlap(prev, c) {
+				if c == primary {
+					u[len(u)-1] = c
+				}
+				continue
+			}
+		}
+		u = append(u, c)
+	}
+	sortCursors(u)
+	return cursorsWithout(u, primary)
+}
+
+func ClearExtraCursors(ta Texta) {
+	ta.SetExtraCursors(nil)
+}
+
+// Puts a cursor at the end of each line of the selection.
+func AddCursorsToLines(ta Texta) {
+	if !ta.SelectionOn() {
+		return
+	}
+	a, b := SelectionStringIndexes(ta)
+	str := ta.Slice(a, b)
+	var cs []*Cursor
+	for i := 0; ; {
+		j := strings.Index(str[i:], "\n")
+		if j < 0 {
+			break
+		}
+		cs = append(cs, &Cursor{Index: a + i + j})
+		i += j + 1
+	}
+	ta.SetSelectionOff()
+	ta.SetCursorIndex(b)
+	p := currentCursor(ta)
+	cs = append(cs, ta.ExtraCursors()...)
+	ta.SetExtraCursors(mergeCursors(append(cs, p), p))
+}
+
+// Selects the next match of the selection with a new cursor. Without a selection, selects the word at the cursor.
+func AddCursorNextMatch(ta Texta) {
+	if !ta.SelectionOn() {
+		SelectWord(ta)
+		return
+	}
+	a, b := SelectionStringIndexes(ta)
+	str := ta.Str()
+	s := str[a:b]
+
+	// search after the last cursor
+	primary := currentCursor(ta)
+	cs := append([]*Cursor{primary}, ta.ExtraCursors()...)
+	sortCursors(cs)
+	_, e := cs[len(cs)-1].indexes()
+	i, ok := findNextString(str, s, e)
+	if !ok {
+		return
+	}
+	c := &Cursor{Index: i + len(s), SelIndex: i, SelOn: true}
+	for _, c2 := range cs {
+		s2, _ := c2.indexes()
+		if s2 == i {
+			// all matches have cursors
+			return
+		}
+	}
+
+	ta.SetExtraCursors(mergeCursors(append(cs, c), c))
+	setCurrentCursor(ta, c)
+	ta.MakeIndexVisible(c.Index)
+}
@@ -1,11 +1,20 @@
 package tautil
 
+import "strings"
+
 func Cut(ta Texta) {
+	u := cursorsSelections(ta)
+	if len(u) == 0 {
+		return
+	}
+	ta.SetClipboardCopy(strings.Join(u, "\n"))
+	forEachCursor(ta, func() { cut(ta) })
+}
+func cut(ta Texta) {
 	if !ta.SelectionOn() {
 		return
 	}
 	a, b := SelectionStringIndexes(ta)
-	ta.SetClipboardCopy(ta.Str()[a:b])
 	ta.EditOpen()
 	ta.EditDelete(a, b)
 	ta.EditClose()
@@ -1,6 +1,9 @@
 package tautil
 
 func Delete(ta Texta) {
+	forEachCursor(ta, func() { del(ta) })
+}
+func del(ta Texta) {
 	var a, b int
 	if ta.SelectionOn() {
 		a, b = SelectionStringIndexes(ta)
@@ -8,7 +11,7 @@ func Delete(ta Texta) {
 	} else {
 		var ok bool
 		a = ta.CursorIndex()
-		_, b, ok = NextRuneIndex(ta.Str(), a)
+		_, b, ok = NextRuneIndex(ta, a)
 		if !ok {
 			return
 		}
@@ -1,8 +1,11 @@
 package tautil
 
 func DuplicateLines(ta Texta) {
+	forEachCursorLines(ta, false, func() { duplicateLines(ta) })
+}
+func duplicateLines(ta Texta) {
 	a, b, hasNewline := linesStringIndexes(ta)
-	s := ta.Str()[a:b]
+	s := ta.Slice(a, b)
 	ta.EditOpen()
 	if !hasNewline {
 		ta.EditInsert(b, "\n")
@@ -1,60 +1,77 @@
 package tautil
 
-import "unicode"
+import (
+	"unicode"
 
+	"github.com/jmigpin/editor/ui/tautil/textbuf"
+)
+
+// Undo tree, branches are kept when editing after an undo.
 type EditHistory struct {
-	q               []*StrEdit // edits queue
-	start, end, cur int        // q positions, start/end are always positive
+	root *ehNode
+	cur  *ehNode // last applied edit, root if none
 }
 
-func NewEditHistory(n int) *EditHistory {
-	return &EditHistory{q: make([]*StrEdit, n)}
+type ehNode struct {
+	parent *ehNode
+	childs []*ehNode
+	redo   int // child followed on redo
+	edit   *StrEdit
 }
-func (h *EditHistory) qmod(index int) **StrEdit {
-	return &h.q[index%len(h.q)]
+
+func NewEditHistory() *EditHistory {
+	h := &EditHistory{}
+	h.Clear()
+	return h
 }
 func (h *EditHistory) PushEdit(edit *StrEdit) {
-	*h.qmod(h.cur) = edit
-	h.cur++
-	h.end = h.cur
-	if h.end-h.start > len(h.q) {
-		h.start = h.end - len(h.q)
-	}
+	n := &ehNode{parent: h.cur, edit: edit}
+	h.cur.childs = append(h.cur.childs, n)
+	h.cur.redo = len(h.cur.childs) - 1
+	h.cur = n
 
 	h.tryToMergeLastTwoEdits()
 }
-func (h *EditHistory) PopUndo(str string) (string, int, bool) {
-	if h.cur-1 < h.start {
-		return "", 0, false // no undos
+func (h *EditHistory) PopUndo(buf *textbuf.Buffer) (int, bool) {
+	if h.cur == h.root {
+		return 0, false // no undos
 	}
-	h.cur--
-	edit := *h.qmod(h.cur)
-	s, i := edit.undos.Apply(str)
-	return s, i, true
+	i := h.cur.edit.undos.Apply(buf)
+	h.cur = h.cur.parent
+	return i, true
 }
-func (h *EditHistory) UnpopRedo(str string) (string, int, bool) {
-	if h.cur == h.end {
-		return "", 0, false // no redos
+func (h *EditHistory) UnpopRedo(buf *textbuf.Buffer) (int, bool) {
+	if len(h.cur.childs) == 0 {
+		return 0, false // no redos
 	}
-	edit := *h.qmod(h.cur)
-	h.cur++
-	s, i := edit.edits.Apply(str)
-	return s, i, true
+	n := h.cur.childs[h.cur.redo]
+	i := n.edit.edits.Apply(buf)
+	h.cur = n
+	return i, true
 }
-func (h *EditHistory) ClearQ() {
-	h.start, h.cur, h.end = 0, 0, 0
-	for i := range h.q {
-		h.q[i] = nil
+func (h *EditHistory) Clear() {
+	h.root = &ehNode{}
+	h.cur = h.root
+}
+
+// Selects the redo branch at the current position by moving d branches. Returns the selected branch and the number of branches.
+func (h *EditHistory) SelectRedoBranch(d int) (int, int) {
+	n := len(h.cur.childs)
+	if n == 0 {
+		return 0, 0
 	}
+	h.cur.redo = ((h.cur.redo+d)%n + n) % n
+	return h.cur.redo, n
 }
+
 func (h *EditHistory) tryToMergeLastTwoEdits() {
-	e1 := h.cur - 2
-	e2 := h.cur - 1
-	if e1 < h.start || e2 < h.start {
+	// can't merge into a branch point or the root
+	p := h.cur.parent
+	if p == h.root || len(p.childs) != 1 {
 		return
 	}
-	edit1 := *h.qmod(e1)
-	edit2 := *h.qmod(e2)
+	edit1 := p.edit
+	edit2 := h.cur.edit
 
 	editIsInsertLetter := func(edit *StrEdit) (int, bool) {
 		if len(edit.edits) != 1 {
@@ -149,34 +166,35 @@ func (h *EditHistory) tryToMergeLastTwoEdits() {
 	edit1.edits = append(edit1.edits, edit2.edits...)
 	edit1.undos = append(edit2.undos, edit1.undos...)
 
-	// remove edit2 from q
+	// remove edit2 from the tree
 	// ok to remove like this since the edit was just added
-	h.cur--
-	h.end--
-	*h.qmod(h.end) = nil
+	p.childs = nil
+	p.redo = 0
+	h.cur = p
 }
 
+// Edits the buffer directly while recording the changes for the history.
 type EditHistoryEdit struct {
-	ostr, str string
-	strEdit   *StrEdit
+	buf     *textbuf.Buffer
+	strEdit *StrEdit
 }
 
-func NewEditHistoryEdit(str string) *EditHistoryEdit {
-	return &EditHistoryEdit{ostr: str, str: str, strEdit: &StrEdit{}}
+func NewEditHistoryEdit(buf *textbuf.Buffer) *EditHistoryEdit {
+	return &EditHistoryEdit{buf: buf, strEdit: &StrEdit{}}
 }
 func (he *EditHistoryEdit) Str() string {
-	return he.str
+	return he.buf.String()
 }
 func (he *EditHistoryEdit) Insert(index int, istr string) {
-	he.str = he.strEdit.Insert(he.str, index, istr)
+	he.strEdit.Insert(he.buf, index, istr)
 }
 func (he *EditHistoryEdit) Delete(index, index2 int) {
-	he.str = he.strEdit.Delete(he.str, index, index2)
+	he.strEdit.Delete(he.buf, index, index2)
 }
-func (he *EditHistoryEdit) Close() (string, *StrEdit, bool) {
-	changed := he.str != he.ostr
+func (he *EditHistoryEdit) Close() (*StrEdit, bool) {
+	changed := !he.strEdit.IsEmpty() && !he.strEdit.isNoOp()
 	if !changed {
-		return "", nil, false
+		return nil, false
 	}
-	return he.str, he.strEdit, true
+	return he.strEdit, true
 }
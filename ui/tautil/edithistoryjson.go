@@ -0,0 +1,111 @@
+package tautil
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Tree nodes encoded as a list, parents come before their childs.
+type ehJSON struct {
+	Nodes []*ehNodeJSON
+	Cur   int
+}
+type ehNodeJSON struct {
+	Parent int // -1 for the root
+	Redo   int
+	Edits  []*ehActionJSON `json:",omitempty"`
+	Undos  []*ehActionJSON `json:",omitempty"`
+}
+type ehActionJSON struct {
+	Insert bool   `json:",omitempty"`
+	Index  int    `json:",omitempty"`
+	Index2 int    `json:",omitempty"`
+	Str    string `json:",omitempty"`
+}
+
+func (h *EditHistory) MarshalJSON() ([]byte, error) {
+	u := &ehJSON{}
+	m := make(map[*ehNode]int)
+	var visit func(n *ehNode, parent int)
+	visit = func(n *ehNode, parent int) {
+		m[n] = len(u.Nodes)
+		nj := &ehNodeJSON{Parent: parent, Redo: n.redo}
+		if n.edit != nil {
+			nj.Edits = encodeEHActions(n.edit.edits)
+			nj.Undos = encodeEHActions(n.edit.undos)
+		}
+		u.Nodes = append(u.Nodes, nj)
+		k := m[n]
+		for _, c := range n.childs {
+			visit(c, k)
+		}
+	}
+	visit(h.root, -1)
+	u.Cur = m[h.cur]
+	return json.Marshal(u)
+}
+func (h *EditHistory) UnmarshalJSON(b []byte) error {
+	var u ehJSON
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if len(u.Nodes) == 0 || u.Cur < 0 || u.Cur >= len(u.Nodes) {
+		return fmt.Errorf("edithistory: bad data")
+	}
+	nodes := make([]*ehNode, len(u.Nodes))
+	for i, nj := range u.Nodes {
+		n := &ehNode{redo: nj.Redo}
+		if i > 0 {
+			if nj.Parent < 0 || nj.Parent >= i {
+				return fmt.Errorf("edithistory: bad parent: %v", nj.Parent)
+			}
+			edits, err := decodeEHActions(nj.Edits)
+			if err != nil {
+				return err
+			}
+			undos, err := decodeEHActions(nj.Undos)
+			if err != nil {
+				return err
+			}
+			n.edit = &StrEdit{edits: edits, undos: undos}
+			n.parent = nodes[nj.Parent]
+			n.parent.childs = append(n.parent.childs, n)
+		}
+		nodes[i] = n
+	}
+	for _, n := range nodes {
+		if n.redo < 0 || (n.redo > 0 && n.redo >= len(n.childs)) {
+			return fmt.Errorf("edithistory: bad redo: %v", n.redo)
+		}
+	}
+	h.root = nodes[0]
+	h.cur = nodes[u.Cur]
+	return nil
+}
+
+func encodeEHActions(u StrEditActions) []*ehActionJSON {
+	var w []*ehActionJSON
+	for _, e := range u {
+		switch t := e.(type) {
+		case *StrEditInsert:
+			w = append(w, &ehActionJSON{Insert: true, Index: t.index, Str: t.str})
+		case *StrEditDelete:
+			w = append(w, &ehActionJSON{Index: t.index, Index2: t.index2})
+		}
+	}
+	return w
+}
+func decodeEHActions(w []*ehActionJSON) (StrEditActions, error) {
+	var u StrEditActions
+	for _, a := range w {
+		if a.Insert {
+			u = append(u, &StrEditInsert{a.Index, a.Str})
+		} else {
+			if a.Index2 < a.Index {
+				return nil, fmt.Errorf("edithistory: bad delete: %v, %v", a.Index, a.Index2)
+			}
+			u = append(u, &StrEditDelete{a.Index, a.Index2})
+		}
+	}
+	return u, nil
+}
@@ -1,14 +1,9 @@
 package tautil
 
-import "strings"
-
 func EndOfLine(ta Texta, sel bool) {
-	ci := ta.CursorIndex()
-	i := strings.Index(ta.Str()[ci:], "\n")
-	if i < 0 {
-		i = len(ta.Str())
-	} else {
-		i += ci
-	}
+	forEachCursor(ta, func() { endOfLine(ta, sel) })
+}
+func endOfLine(ta Texta, sel bool) {
+	i := ta.LineEnd(ta.CursorIndex())
 	updateSelection(ta, sel, i)
 }
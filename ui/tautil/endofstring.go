@@ -1,6 +1,6 @@
 package tautil
 
 func EndOfString(ta Texta, sel bool) {
-	i := len(ta.Str())
+	i := ta.Len()
 	updateSelection(ta, sel, i)
 }
@@ -1,20 +1,129 @@
 package tautil
 
 import (
+	"regexp"
 	"strings"
 )
 
-func Find(ta Texta, str string) {
+type FindOpt struct {
+	Regexp        bool
+	CaseSensitive bool
+	WholeWord     bool
+}
+
+// Compiles the search string. Non regexp strings are matched literally. In regexps, ^ and $ match at the lines start and end.
+func (opt *FindOpt) Compile(str string) (*regexp.Regexp, error) {
+	if opt.Regexp {
+		str = "(?m)" + str
+	} else {
+		str = regexp.QuoteMeta(str)
+	}
+	if opt.WholeWord {
+		str = `\b(?:` + str + `)\b`
+	}
+	if !opt.CaseSensitive {
+		str = "(?i)" + str
+	}
+	return regexp.Compile(str)
+}
+
+func Find(ta Texta, str string, opt *FindOpt) error {
 	if str == "" {
-		return
+		return nil
+	}
+	re, err := opt.Compile(str)
+	if err != nil {
+		return err
+	}
+	FindRegexpFromIndex(ta, re, ta.CursorIndex(), false)
+	return nil
+}
+
+// Selects the next match after index, or the previous match before index. Returns false if there are no matches.
+func FindRegexpFromIndex(ta Texta, re *regexp.Regexp, index int, prev bool) bool {
+	var a, b int
+	var ok bool
+	if prev {
+		a, b, ok = findPrevRegexp(ta.Str(), re, index)
+	} else {
+		a, b, ok = findNextRegexp(ta.Str(), re, index)
 	}
-	index, ok := findNextString(ta.Str(), str, ta.CursorIndex())
 	if ok {
-		i := index + len(str)
-		ta.SetSelection(index, i)
-		ta.MakeIndexVisibleAtCenter(i)
+		ta.SetSelection(a, b)
+		ta.MakeIndexVisibleAtCenter(b)
 	}
+	return ok
 }
+
+// Searches from index, wrapping around to the start. Empty matches at index are skipped to allow repeating the search.
+func findNextRegexp(text string, re *regexp.Regexp, index int) (int, int, bool) {
+	// searching from the line start keeps the anchors (ex: ^, \b) correct
+	ls := strings.LastIndexByte(text[:index], '\n') + 1
+	a, b, ok := 0, 0, false
+	forEachMatch(text, re, ls, func(a2, b2 int) bool {
+		if a2 > index || (a2 == index && b2 > index) {
+			a, b, ok = a2, b2, true
+			return false
+		}
+		return true
+	})
+	if ok {
+		return a, b, true
+	}
+	// wrap around
+	if loc := re.FindStringIndex(text); loc != nil {
+		return loc[0], loc[1], true
+	}
+	return 0, 0, false
+}
+
+// Searches backwards from index, wrapping around to the end.
+func findPrevRegexp(text string, re *regexp.Regexp, index int) (int, int, bool) {
+	if a, b, ok := lastMatchBefore(text, re, index); ok {
+		return a, b, true
+	}
+	// wrap around, including an empty match at the end
+	return lastMatchBefore(text, re, len(text)+1)
+}
+
+// Last match that starts before index. Searches windows that grow backwards from index, each starting at a line start.
+func lastMatchBefore(text string, re *regexp.Regexp, index int) (int, int, bool) {
+	for size := 4096; ; size *= 2 {
+		w := 0
+		if k := index - size; k > 0 {
+			w = strings.LastIndexByte(text[:k], '\n') + 1
+		}
+		a, b, ok := 0, 0, false
+		forEachMatch(text, re, w, func(a2, b2 int) bool {
+			if a2 >= index {
+				return false
+			}
+			a, b, ok = a2, b2, true
+			return true
+		})
+		if ok || w == 0 {
+			return a, b, ok
+		}
+	}
+}
+
+// Calls fn with the matches in text[start:] in order until it returns false. The matches are searched in growing batches, the search doesn't go much further than the last match needed.
+func forEachMatch(text string, re *regexp.Regexp, start int, fn func(a, b int) bool) {
+	seen := 0
+	for n := 8; ; n *= 2 {
+		locs := re.FindAllStringIndex(text[start:], n)
+		for _, loc := range locs[seen:] {
+			if !fn(start+loc[0], start+loc[1]) {
+				return
+			}
+		}
+		if len(locs) < n {
+			return
+		}
+		seen = len(locs)
+	}
+}
+
 func findNextString(text, str string, index int) (int, bool) {
 	// ignore case
 	str = strings.ToLower(str)
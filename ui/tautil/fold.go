@@ -0,0 +1,124 @@
+package tautil
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jmigpin/editor/drawutil2"
+	"github.com/jmigpin/editor/drawutil2/loopers"
+)
+
+// Range of lines to hide when folding at the index line: the lines inside a bracket block opened in the line, or the following lines with more indentation. Falls back to the enclosing brackets.
+func FoldRange(ta Texta, index int) (int, int, bool) {
+	r := newTextReader(ta)
+	ls := ta.LineStart(index)
+	if a, b, ok := foldRangeBrackets(ta, r, ls); ok {
+		return a, b, true
+	}
+	if a, b, ok := foldRangeIndent(ta, ls); ok {
+		return a, b, true
+	}
+	if i, ok := enclosingBracket(r, index, ta.InStringOrComment); ok {
+		return foldRangeBrackets(ta, r, ta.LineStart(i))
+	}
+	return 0, 0, false
+}
+
+// Fold ranges of the lines without indentation (ex: go functions bodies, imports, composite literals).
+func FoldAllRanges(ta Texta) [][2]int {
+	r := newTextReader(ta)
+	var u [][2]int
+	for ls := 0; ls < r.n; {
+		le, _ := lineEndNextIndex(ta, ls)
+		line := ta.Slice(ls, le)
+		if strings.TrimSpace(line) != "" && indentWidth(line) == 0 {
+			a, b, ok := foldRangeBrackets(ta, r, ls)
+			if !ok {
+				a, b, ok = foldRangeIndent(ta, ls)
+			}
+			if ok {
+				u = append(u, [2]int{a, b})
+				le = b
+			}
+		}
+		ls = le
+	}
+	return u
+}
+
+// Lines after the line start until the line of the closing bracket, for the last bracket of the line that closes in a later line.
+func foldRangeBrackets(ta Texta, r *textReader, ls int) (int, int, bool) {
+	le, hasNewline := lineEndNextIndex(ta, ls)
+	if !hasNewline {
+		return 0, 0, false
+	}
+	for k := le - 2; k >= ls; k-- {
+		c := r.byteAt(k)
+		if (c != '(' && c != '[' && c != '{') || ta.InStringOrComment(k) {
+			continue
+		}
+		j, ok := matchBracket(r, k, ta.InStringOrComment)
+		if !ok || j < le {
+			continue
+		}
+		b := ta.LineStart(j)
+		if b <= le {
+			return 0, 0, false
+		}
+		return le, b, true
+	}
+	return 0, 0, false
+}
+
+// Following lines with more indentation. Blank lines at the end are not included.
+func foldRangeIndent(ta Texta, ls int) (int, int, bool) {
+	le, hasNewline := lineEndNextIndex(ta, ls)
+	line := ta.Slice(ls, le)
+	if !hasNewline || strings.TrimSpace(line) == "" {
+		return 0, 0, false
+	}
+	indent := indentWidth(line)
+	end := le
+	for k, n := le, ta.Len(); k < n; {
+		e, _ := lineEndNextIndex(ta, k)
+		line := ta.Slice(k, e)
+		if strings.TrimSpace(line) != "" {
+			if indentWidth(line) <= indent {
+				break
+			}
+			end = e
+		}
+		k = e
+	}
+	if end == le {
+		return 0, 0, false
+	}
+	return le, end, true
+}
+
+func indentWidth(line string) int {
+	w := 0
+	for _, ru := range line {
+		switch ru {
+		case ' ':
+			w++
+		case '\t':
+			w += drawutil2.TabWidth
+		default:
+			return w
+		}
+	}
+	return w
+}
+
+// Fold hiding the index. The placeholder line of a fold has the index of the fold start.
+func foldAt(ta Texta, index int) (*loopers.SelectionIndexes, bool) {
+	folds := ta.Folds()
+	k := sort.Search(len(folds), func(i int) bool {
+		return folds[i].End > index
+	})
+	if k < len(folds) && folds[k].Start <= index {
+		return folds[k], true
+	}
+	return nil, false
+}
@@ -0,0 +1,153 @@
+package tautil
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Lines sampled to detect the indentation.
+var indentSampleLines = 2000
+
+// Bytes from the start of the texta sampled to detect its indentation.
+var indentSampleSize = 128 * 1024
+
+// Indentation unit used in the string: a tab, or the most common indentation step of the lines indented with spaces.
+func DetectIndent(str string) (string, bool) {
+	tabs, spaces := 0, 0
+	steps := map[int]int{}
+	prev := 0 // previous line spaces indentation
+	for i, n := 0, 0; i < len(str) && n < indentSampleLines; n++ {
+		e, _ := lineEndIndexNextIndex(str, i)
+		line := str[i:e]
+		i = e
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		switch line[0] {
+		case '\t':
+			tabs++
+			prev = 0
+		case ' ':
+			spaces++
+			k := len(line) - len(strings.TrimLeft(line, " "))
+			// ignore odd alignments (ex: " * " in block comments)
+			if d := k - prev; d >= 2 && d <= 8 {
+				steps[d]++
+			}
+			prev = k
+		default:
+			prev = 0
+		}
+	}
+	if tabs > 0 && tabs >= spaces {
+		return "\t", true
+	}
+	best := 0
+	for d, c := range steps {
+		if c > steps[best] || (c == steps[best] && d < best) {
+			best = d
+		}
+	}
+	if best == 0 {
+		return "", false
+	}
+	return strings.Repeat(" ", best), true
+}
+
+// Indentation unit of the texta or detected from the string, defaults to a tab.
+func indentUnit(ta Texta) string {
+	if u := ta.IndentUnit(); u != "" {
+		return u
+	}
+	n := ta.Len()
+	if n > indentSampleSize {
+		n = indentSampleSize
+	}
+	if u, ok := DetectIndent(ta.Slice(0, n)); ok {
+		return u
+	}
+	return "\t"
+}
+
+// Leading spaces and tabs of the line at the index.
+func lineIndent(ta Texta, index int) string {
+	line := ta.Slice(ta.LineStart(index), ta.LineEnd(index))
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// Replaces the leading spaces of the lines with tabs, n spaces per tab. Works on the selection lines, or on the whole string.
+func IndentToTabs(ta Texta, n int) {
+	reindent(ta, func(w int) string {
+		return strings.Repeat("\t", w/n) + strings.Repeat(" ", w%n)
+	}, n)
+}
+
+// Replaces the leading tabs of the lines with spaces, tab stops every n spaces. Works on the selection lines, or on the whole string.
+func IndentToSpaces(ta Texta, n int) {
+	reindent(ta, func(w int) string {
+		return strings.Repeat(" ", w)
+	}, n)
+}
+
+// Replaces the lines indentation with fn of the indentation width, in one edit.
+func reindent(ta Texta, fn func(width int) string, tabWidth int) {
+	if tabWidth <= 0 {
+		return
+	}
+	a, b := 0, ta.Len()
+	if ta.SelectionOn() {
+		a, b, _ = linesStringIndexes(ta)
+	}
+	str := ta.Slice(a, b)
+	ci := ta.CursorIndex() - a
+
+	var buf bytes.Buffer
+	ci2 := ci
+	for i := 0; i < len(str); {
+		e, _ := lineEndIndexNextIndex(str, i)
+		line := str[i:e]
+		k := len(line) - len(strings.TrimLeft(line, " \t"))
+		// width of the indentation
+		w := 0
+		for _, ru := range line[:k] {
+			if ru == '\t' {
+				w += tabWidth - w%tabWidth
+			} else {
+				w++
+			}
+		}
+		ws := fn(w)
+		// keep the cursor in the line text
+		if ci >= i && ci < e {
+			if ci < i+k {
+				ci2 = buf.Len() + len(ws)
+			} else {
+				ci2 = buf.Len() + len(ws) + ci - (i + k)
+			}
+		}
+		buf.WriteString(ws)
+		buf.WriteString(line[k:])
+		i = e
+	}
+	str2 := buf.String()
+	if ci >= len(str) {
+		ci2 = len(str2)
+	}
+	if str2 == str {
+		return
+	}
+
+	sel := ta.SelectionOn()
+	ta.EditOpen()
+	ta.EditDelete(a, b)
+	ta.EditInsert(a, str2)
+	ta.EditClose()
+
+	if sel {
+		// don't select newline as last char
+		c := previousRuneIndexIfLastIsNewline(str2)
+		ta.SetSelection(a, a+c)
+	} else {
+		ta.SetCursorIndex(a + ci2)
+	}
+}
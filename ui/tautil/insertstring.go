@@ -1,6 +1,26 @@
 package tautil
 
+import "strings"
+
+// With multiple cursors, a string with one line per cursor is inserted line by line (ex: pasting a block selection).
 func InsertString(ta Texta, s string) {
+	n := len(ta.ExtraCursors()) + 1
+	lines := strings.Split(s, "\n")
+	if len(lines) != n {
+		// ignore a final newline
+		lines = strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	}
+	if n > 1 && len(lines) == n {
+		k := 0
+		forEachCursor(ta, func() {
+			insertString(ta, lines[k])
+			k++
+		})
+		return
+	}
+	forEachCursor(ta, func() { insertString(ta, s) })
+}
+func insertString(ta Texta, s string) {
 	ta.EditOpen()
 	if ta.SelectionOn() {
 		// remove selection
@@ -2,7 +2,6 @@ package tautil
 
 import (
 	"image"
-	"strings"
 
 	"golang.org/x/image/math/fixed"
 )
@@ -17,20 +16,26 @@ func MoveCursorToPoint(ta Texta, p *image.Point, sel bool) {
 	// set primary copy
 	if ta.SelectionOn() {
 		a, b := SelectionStringIndexes(ta)
-		s := ta.Str()[a:b]
+		s := ta.Slice(a, b)
 		ta.SetPrimaryCopy(s)
 	}
 }
 
 func MoveCursorRight(ta Texta, sel bool) {
-	_, i, ok := NextRuneIndex(ta.Str(), ta.CursorIndex())
+	forEachCursor(ta, func() { moveCursorRight(ta, sel) })
+}
+func moveCursorRight(ta Texta, sel bool) {
+	_, i, ok := NextRuneIndex(ta, ta.CursorIndex())
 	if !ok {
 		return
 	}
 	updateSelection(ta, sel, i)
 }
 func MoveCursorLeft(ta Texta, sel bool) {
-	_, i, ok := PreviousRuneIndex(ta.Str(), ta.CursorIndex())
+	forEachCursor(ta, func() { moveCursorLeft(ta, sel) })
+}
+func moveCursorLeft(ta Texta, sel bool) {
+	_, i, ok := PreviousRuneIndex(ta, ta.CursorIndex())
 	if !ok {
 		return
 	}
@@ -38,29 +43,52 @@ func MoveCursorLeft(ta Texta, sel bool) {
 }
 
 func MoveCursorUp(ta Texta, sel bool) {
+	forEachCursor(ta, func() { moveCursorUp(ta, sel) })
+}
+func moveCursorUp(ta Texta, sel bool) {
 	p := ta.IndexPoint(ta.CursorIndex())
 	p.Y -= ta.LineHeight()
 	i := ta.PointIndex(p)
+	if f, ok := foldAt(ta, i); ok {
+		// skip the placeholder line
+		i = f.Start - 1
+		if i < 0 {
+			i = f.End
+		}
+	}
 	updateSelection(ta, sel, i)
 }
 func MoveCursorDown(ta Texta, sel bool) {
+	forEachCursor(ta, func() { moveCursorDown(ta, sel) })
+}
+func moveCursorDown(ta Texta, sel bool) {
 	p := ta.IndexPoint(ta.CursorIndex())
 	p.Y += ta.LineHeight()
 	i := ta.PointIndex(p)
+	if f, ok := foldAt(ta, i); ok {
+		// skip the placeholder line
+		i = f.End
+	}
 	updateSelection(ta, sel, i)
 }
 
 func MoveCursorJumpLeft(ta Texta, sel bool) {
-	i := jumpLeftIndex(ta.Str(), ta.CursorIndex())
+	forEachCursor(ta, func() { moveCursorJumpLeft(ta, sel) })
+}
+func moveCursorJumpLeft(ta Texta, sel bool) {
+	i := jumpLeftIndex(ta, ta.CursorIndex())
 	updateSelection(ta, sel, i)
 }
 func MoveCursorJumpRight(ta Texta, sel bool) {
-	i := jumpRightIndex(ta.Str(), ta.CursorIndex())
+	forEachCursor(ta, func() { moveCursorJumpRight(ta, sel) })
+}
+func moveCursorJumpRight(ta Texta, sel bool) {
+	i := jumpRightIndex(ta, ta.CursorIndex())
 	updateSelection(ta, sel, i)
 }
 
-func jumpLeftIndex(str string, index int) int {
-	i := strings.LastIndexFunc(str[:index], endOfNextWord())
+func jumpLeftIndex(ta Texta, index int) int {
+	i := lastIndexFunc(ta, index, endOfNextWord())
 	if i < 0 {
 		i = 0
 	} else {
@@ -68,12 +96,12 @@ func jumpLeftIndex(str string, index int) int {
 	}
 	return i
 }
-func jumpRightIndex(str string, index int) int {
-	i := strings.IndexFunc(str[index:], endOfNextWord())
+func jumpRightIndex(ta Texta, index int) int {
+	i := indexFunc(ta, index, endOfNextWord())
 	if i < 0 {
-		i = len(str[index:])
+		i = ta.Len()
 	}
-	return index + i
+	return i
 }
 
 func endOfNextWord() func(rune) bool {
@@ -1,15 +1,18 @@
 package tautil
 
 func MoveLineUp(ta Texta) {
+	forEachCursorLines(ta, false, func() { moveLineUp(ta) })
+}
+func moveLineUp(ta Texta) {
 	a, b, hasNewline := linesStringIndexes(ta)
 	if a == 0 {
 		// already at the first line
 		return
 	}
-	s := ta.Str()[a:b]
+	s := ta.Slice(a, b)
 	ta.EditOpen()
 	ta.EditDelete(a, b)
-	a2 := lineStartIndex(ta.Str(), a-1) // previous line, -1 is size of '\n'
+	a2 := ta.LineStart(a - 1) // previous line, -1 is size of '\n'
 	if !hasNewline {
 		ta.EditDelete(a-1, a) // remove newline to honor the moving line
 		s = s + "\n"
@@ -18,7 +21,7 @@ func MoveLineUp(ta Texta) {
 	ta.EditClose()
 
 	if ta.SelectionOn() {
-		_, b2, ok := PreviousRuneIndex(ta.Str(), a2+len(s))
+		_, b2, ok := PreviousRuneIndex(ta, a2+len(s))
 		if !ok {
 			return
 		}
@@ -29,15 +32,18 @@ func MoveLineUp(ta Texta) {
 	}
 }
 func MoveLineDown(ta Texta) {
+	forEachCursorLines(ta, true, func() { moveLineDown(ta) })
+}
+func moveLineDown(ta Texta) {
 	a, b, _ := linesStringIndexes(ta)
-	if b == len(ta.Str()) {
+	if b == ta.Len() {
 		// already at the last line
 		return
 	}
-	s := ta.Str()[a:b]
+	s := ta.Slice(a, b)
 	ta.EditOpen()
 	ta.EditDelete(a, b)
-	a2, hasNewline := lineEndIndexNextIndex(ta.Str(), a)
+	a2, hasNewline := lineEndNextIndex(ta, a)
 	if !hasNewline {
 		// remove newline from previous
 		s = s[:len(s)-1]
@@ -52,7 +58,7 @@ func MoveLineDown(ta Texta) {
 		b2 := a2 + len(s)
 		if hasNewline {
 			var ok bool
-			_, b2, ok = PreviousRuneIndex(ta.Str(), b2)
+			_, b2, ok = PreviousRuneIndex(ta, b2)
 			if !ok {
 				return
 			}
@@ -1,21 +1,53 @@
 package tautil
 
-import "strings"
+import (
+	"bytes"
+	"regexp"
+)
 
-func Replace(ta Texta, old, new string) {
+// Replaces inside the selection if it is on, otherwise in the whole text. Regexp replacements expand $1 and ${name}. Returns the number of replacements.
+func Replace(ta Texta, old, new string, opt *FindOpt) (int, error) {
+	re, err := opt.Compile(old)
+	if err != nil {
+		return 0, err
+	}
+
+	a, b := 0, ta.Len()
+	if ta.SelectionOn() {
+		a, b = SelectionStringIndexes(ta)
+	}
+	s, n := ReplaceString(ta.Slice(a, b), re, new, opt.Regexp)
+	if n == 0 {
+		return 0, nil
+	}
+
+	ta.EditOpen()
+	ta.EditDelete(a, b)
+	ta.EditInsert(a, s)
+	ta.EditClose()
 	if ta.SelectionOn() {
-		a, b := SelectionStringIndexes(ta)
-		s := strings.Replace(ta.Str()[a:b], old, new, -1)
-		ta.EditOpen()
-		ta.EditDelete(a, b)
-		ta.EditInsert(a, s)
-		ta.EditClose()
-		ta.SetCursorIndex(a + len(s))
-	} else {
-		s := strings.Replace(ta.Str(), old, new, -1)
-		ta.EditOpen()
-		ta.EditDelete(0, len(ta.Str()))
-		ta.EditInsert(0, s)
-		ta.EditClose()
+		ta.SetSelection(a, a+len(s))
+	}
+	return n, nil
+}
+
+// Replaces all matches, expanding $1 and ${name} if expand is true. Returns the new string and the number of replacements.
+func ReplaceString(src string, re *regexp.Regexp, new string, expand bool) (string, int) {
+	locs := re.FindAllStringSubmatchIndex(src, -1)
+	if len(locs) == 0 {
+		return src, 0
+	}
+	var buf bytes.Buffer
+	k := 0
+	for _, loc := range locs {
+		buf.WriteString(src[k:loc[0]])
+		if expand {
+			buf.Write(re.ExpandString(nil, new, src, loc))
+		} else {
+			buf.WriteString(new)
+		}
+		k = loc[1]
 	}
+	buf.WriteString(src[k:])
+	return buf.String(), len(locs)
 }
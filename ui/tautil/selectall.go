@@ -1,5 +1,5 @@
 package tautil
 
 func SelectAll(ta Texta) {
-	ta.SetSelection(0, len(ta.Str()))
+	ta.SetSelection(0, ta.Len())
 }
@@ -0,0 +1,90 @@
+package tautil
+
+import "golang.org/x/image/math/fixed"
+
+// Rectangular selection between two points (text coordinates, p1 is the cursor side). Each line of the block gets a cursor with the line selection, allowing the editing operations to work per line.
+func SelectBlock(ta Texta, p0, p1 *fixed.Point26_6) {
+	lh := ta.LineHeight()
+	if lh == 0 {
+		return
+	}
+	lineY := func(y fixed.Int26_6) fixed.Int26_6 {
+		if y < 0 {
+			y = 0
+		}
+		// last line
+		if h := ta.StrHeight(); y >= h {
+			y = h - 1
+		}
+		return y / lh * lh
+	}
+
+	x0, x1 := p0.X, p1.X
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if x0 < 0 {
+		x0 = 0
+	}
+	if x1 < 0 {
+		x1 = 0
+	}
+	y0, y1 := lineY(p0.Y), lineY(p1.Y)
+	up := y1 < y0
+	if up {
+		y0, y1 = y1, y0
+	}
+
+	var cs []*Cursor
+	for y := y0; y <= y1; y += lh {
+		a := ta.PointIndex(&fixed.Point26_6{x0, y})
+		b := ta.PointIndex(&fixed.Point26_6{x1, y})
+		if _, ok := foldAt(ta, a); ok {
+			continue // placeholder line
+		}
+		c := &Cursor{Index: b, SelIndex: a, SelOn: a != b}
+		if p1.X < p0.X {
+			c.Index, c.SelIndex = a, b
+		}
+		cs = append(cs, c)
+	}
+
+	if len(cs) == 0 {
+		return
+	}
+
+	// primary cursor at the p1 line
+	primary := cs[len(cs)-1]
+	if up {
+		primary = cs[0]
+	}
+	setCurrentCursor(ta, primary)
+	ta.SetExtraCursors(mergeCursors(cs, primary))
+
+	r := &fixed.Rectangle26_6{
+		Min: fixed.Point26_6{x0, y0},
+		Max: fixed.Point26_6{x1, y1 + lh},
+	}
+	ta.SetSelectionBlock(r)
+}
+
+// Selections of all the cursors, in text order. Cursors without a selection give an empty string (ex: block lines shorter than the block), unless none has a selection.
+func cursorsSelections(ta Texta) []string {
+	cs := append([]*Cursor{currentCursor(ta)}, ta.ExtraCursors()...)
+	sortCursors(cs)
+	var u []string
+	sel := false
+	for _, c := range cs {
+		s := ""
+		if c.SelOn {
+			a, b := c.indexes()
+			s = ta.Slice(a, b)
+			sel = true
+		}
+		u = append(u, s)
+	}
+	if !sel {
+		return nil
+	}
+	return u
+}
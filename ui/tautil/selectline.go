@@ -8,7 +8,7 @@ func SelectLine(ta Texta) {
 	// set primary copy
 	if ta.SelectionOn() {
 		a, b := SelectionStringIndexes(ta)
-		s := ta.Str()[a:b]
+		s := ta.Slice(a, b)
 		ta.SetPrimaryCopy(s)
 	}
 }
@@ -1,25 +1,23 @@
 package tautil
 
-import "strings"
-
 func SelectWord(ta Texta) {
 	index := ta.CursorIndex()
-	a := wordLeftIndex(ta.Str(), index)
-	b := wordRightIndex(ta.Str(), index)
+	a := wordLeftIndex(ta, index)
+	b := wordRightIndex(ta, index)
 	ta.SetSelection(a, b)
 
 	// set primary copy
 	if ta.SelectionOn() {
 		a, b := SelectionStringIndexes(ta)
-		s := ta.Str()[a:b]
+		s := ta.Slice(a, b)
 		ta.SetPrimaryCopy(s)
 	}
 }
 
-func wordLeftIndex(str string, index int) int {
+func wordLeftIndex(ta Texta, index int) int {
 	typ := 0
 
-	ru, _, ok := NextRuneIndex(str, index)
+	ru, _, ok := NextRuneIndex(ta, index)
 	if ok {
 		typ = wordType(ru)
 	}
@@ -32,7 +30,7 @@ func wordLeftIndex(str string, index int) int {
 		}
 		return typ2 != typ
 	}
-	i := strings.LastIndexFunc(str[:index], fn)
+	i := lastIndexFunc(ta, index, fn)
 	if i < 0 {
 		i = 0
 	} else {
@@ -40,7 +38,7 @@ func wordLeftIndex(str string, index int) int {
 	}
 	return i
 }
-func wordRightIndex(str string, index int) int {
+func wordRightIndex(ta Texta, index int) int {
 	typ := 0
 	fn := func(ru rune) bool {
 		typ2 := wordType(ru)
@@ -50,11 +48,11 @@ func wordRightIndex(str string, index int) int {
 		}
 		return typ2 != typ
 	}
-	i := strings.IndexFunc(str[index:], fn)
+	i := indexFunc(ta, index, fn)
 	if i < 0 {
-		i = len(str[index:])
+		i = ta.Len()
 	}
-	return index + i
+	return i
 }
 func wordType(ru rune) int {
 	if isWordRune(ru) {
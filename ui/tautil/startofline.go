@@ -3,10 +3,13 @@ package tautil
 import "unicode"
 
 func StartOfLine(ta Texta, sel bool) {
-	i := lineStartIndex(ta.Str(), ta.CursorIndex())
+	forEachCursor(ta, func() { startOfLine(ta, sel) })
+}
+func startOfLine(ta Texta, sel bool) {
+	i := ta.LineStart(ta.CursorIndex())
 
 	// stop at first non blank rune from the left
-	t := ta.Str()[i:ta.CursorIndex()]
+	t := ta.Slice(i, ta.CursorIndex())
 	for j, ru := range t {
 		if !unicode.IsSpace(ru) {
 			i += j
@@ -1,49 +1,74 @@
 package tautil
 
+import "github.com/jmigpin/editor/ui/tautil/textbuf"
+
 type StrEdit struct {
 	edits StrEditActions
 	undos StrEditActions
 }
 
-func (se *StrEdit) Insert(str string, index int, istr string) string {
+func (se *StrEdit) Insert(buf *textbuf.Buffer, index int, istr string) {
 	if len(istr) == 0 {
-		return str
+		return
 	}
 	ins := &StrEditInsert{index, istr}
 	se.edits = append(se.edits, ins)
 	se.undos = append(StrEditActions{ins.getUndo()}, se.undos...)
-	return ins.apply(str)
+	ins.apply(buf)
 }
-func (se *StrEdit) Delete(str string, index, index2 int) string {
+func (se *StrEdit) Delete(buf *textbuf.Buffer, index, index2 int) {
 	if index == index2 {
-		return str
+		return
 	}
 	del := &StrEditDelete{index, index2}
 	se.edits = append(se.edits, del)
-	se.undos = append(StrEditActions{del.getUndo(str)}, se.undos...)
-	return del.apply(str)
+	se.undos = append(StrEditActions{del.getUndo(buf)}, se.undos...)
+	del.apply(buf)
 }
 func (se *StrEdit) IsEmpty() bool {
 	return len(se.edits) == 0
 }
 
+// Detects edits that leave the string unchanged (ex: replace with the same content) without comparing the full strings.
+func (se *StrEdit) isNoOp() bool {
+	if len(se.edits)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(se.edits); i += 2 {
+		del, ok := se.edits[i].(*StrEditDelete)
+		if !ok {
+			return false
+		}
+		ins, ok := se.edits[i+1].(*StrEditInsert)
+		if !ok || ins.index != del.index {
+			return false
+		}
+		// undo of the delete holds the deleted string
+		u := se.undos[len(se.undos)-1-i].(*StrEditInsert)
+		if u.str != ins.str {
+			return false
+		}
+	}
+	return true
+}
+
 type StrEditActions []interface{} // inserts/deletes
 
-func (u StrEditActions) Apply(str string) (string, int) {
+func (u StrEditActions) Apply(buf *textbuf.Buffer) int {
 	i := 0
 	for _, e := range u {
 		switch t0 := e.(type) {
 		case *StrEditInsert:
-			str = t0.apply(str)
+			t0.apply(buf)
 			i = t0.index + len(t0.str)
 		case *StrEditDelete:
-			str = t0.apply(str)
+			t0.apply(buf)
 			i = t0.index
 		default:
 			panic("!")
 		}
 	}
-	return str, i
+	return i
 }
 
 type StrEditInsert struct {
@@ -51,8 +76,8 @@ type StrEditInsert struct {
 	str   string
 }
 
-func (u *StrEditInsert) apply(str string) string {
-	return str[:u.index] + u.str + str[u.index:]
+func (u *StrEditInsert) apply(buf *textbuf.Buffer) {
+	buf.Insert(u.index, u.str)
 }
 func (u *StrEditInsert) getUndo() *StrEditDelete {
 	return &StrEditDelete{u.index, u.index + len(u.str)}
@@ -63,9 +88,9 @@ type StrEditDelete struct {
 	index2 int
 }
 
-func (u *StrEditDelete) apply(str string) string {
-	return str[:u.index] + str[u.index2:]
+func (u *StrEditDelete) apply(buf *textbuf.Buffer) {
+	buf.Delete(u.index, u.index2)
 }
-func (u *StrEditDelete) getUndo(str string) *StrEditInsert {
-	return &StrEditInsert{u.index, str[u.index:u.index2]}
+func (u *StrEditDelete) getUndo(buf *textbuf.Buffer) *StrEditInsert {
+	return &StrEditInsert{u.index, buf.Slice(u.index, u.index2)}
 }
@@ -1,18 +1,23 @@
 package tautil
 
+// Inserts the indentation unit (tab or spaces), at the lines start if the selection is on.
 func TabRight(ta Texta) {
+	forEachCursor(ta, func() { tabRight(ta) })
+}
+func tabRight(ta Texta) {
+	unit := indentUnit(ta)
 	if !ta.SelectionOn() {
-		InsertString(ta, "\t")
+		insertString(ta, unit)
 		return
 	}
 
 	a, b, _ := linesStringIndexes(ta)
 
-	str := ta.Str()[a:b]
+	str := ta.Slice(a, b)
 
 	// insert at line start
 	for i := 0; i < len(str); i, _ = lineEndIndexNextIndex(str, i) {
-		str = str[:i] + string('\t') + str[i:]
+		str = str[:i] + unit + str[i:]
 	}
 
 	// replace
@@ -26,19 +31,32 @@ func TabRight(ta Texta) {
 
 	ta.SetSelection(a, a+c)
 }
+
+// Removes one indentation unit from the lines start: a tab, or up to the unit number of spaces.
 func TabLeft(ta Texta) {
+	forEachCursorLines(ta, false, func() { tabLeft(ta) })
+}
+func tabLeft(ta Texta) {
 	a, b, _ := linesStringIndexes(ta)
 
-	str := ta.Str()[a:b]
+	n := len(indentUnit(ta)) // spaces to remove (1 for a tab unit)
+
+	str := ta.Slice(a, b)
 
 	// remove from line start
 	altered := false
-	deletions := 0
 	for i := 0; i < len(str); i, _ = lineEndIndexNextIndex(str, i) {
-		if str[i] == '\t' || str[i] == ' ' {
+		k := 0
+		if str[i] == '\t' {
+			k = 1
+		} else {
+			for k < n && i+k < len(str) && str[i+k] == ' ' {
+				k++
+			}
+		}
+		if k > 0 {
 			altered = true
-			deletions++
-			str = str[:i] + str[i+1:] // +1 is length of '\t' or ' '
+			str = str[:i] + str[i+k:]
 		}
 	}
 
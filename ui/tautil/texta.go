@@ -3,11 +3,19 @@ package tautil
 import (
 	"image"
 
+	"github.com/jmigpin/editor/drawutil2/loopers"
 	"golang.org/x/image/math/fixed"
 )
 
 type Texta interface {
-	Str() string
+	Str() string // builds the full string, edits should use the methods below
+
+	Len() int
+	Slice(a, b int) string
+	ReadRuneAt(int) (rune, int)     // size zero at the end
+	ReadLastRuneAt(int) (rune, int) // size zero at the start
+	LineStart(int) int              // index after the newline before the index
+	LineEnd(int) int                // index of the newline at or after the index, or the length
 
 	EditOpen()
 	EditInsert(index int, str string)
@@ -22,6 +30,17 @@ type Texta interface {
 	SelectionIndex() int
 	SetSelection(int, int) // selection/cursor indexes
 
+	ExtraCursors() []*Cursor
+	SetExtraCursors([]*Cursor)
+	SetSelectionBlock(*fixed.Rectangle26_6) // drawing only, cleared on changes
+
+	Folds() []*loopers.SelectionIndexes // sorted, hidden lines
+
+	InStringOrComment(int) bool  // from the syntax highlighting
+	CommentStyle() *CommentStyle // nil uses the default
+	IndentUnit() string          // empty detects from the string
+
+	MakeIndexVisible(int)
 	MakeIndexVisibleAtCenter(int)
 	WarpPointerToIndexIfVisible(int)
 
@@ -0,0 +1,496 @@
+// Piece table text buffer.
+package textbuf
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Edits only change the pieces list, their cost doesn't depend on the text size.
+// Reading is done with Slice and ReadRuneAt. The full string is built on demand and cached until the next edit.
+type Buffer struct {
+	orig   string // original text, read only
+	add    []byte // appended text, append only
+	pieces []piece
+	n      int // text length
+
+	str   string // cached full string
+	strOk bool
+
+	change   Change // changed range since the last TakeChange
+	changeOk bool
+
+	newlines int // newlines in the text
+	anchor   struct {
+		index, line int // line (starting at 1) of a known index
+	}
+
+	// last piece found, lookups close to it don't walk from the first piece
+	cache struct {
+		i, o int // piece index and its offset in the text
+	}
+}
+
+type piece struct {
+	add   bool // piece from the add buffer
+	start int  // start in the source buffer
+	n     int
+}
+
+func NewBuffer(str string) *Buffer {
+	b := &Buffer{}
+	b.Reset(str)
+	return b
+}
+
+func (b *Buffer) Reset(str string) {
+	b.addChange(0, b.n, len(str))
+	b.orig = str
+	b.add = nil
+	b.pieces = nil
+	if len(str) > 0 {
+		b.pieces = []piece{{start: 0, n: len(str)}}
+	}
+	b.n = len(str)
+	b.str = str
+	b.strOk = true
+	b.newlines = strings.Count(str, "\n")
+	b.anchor.index, b.anchor.line = 0, 1
+	b.cache.i, b.cache.o = 0, 0
+}
+
+func (b *Buffer) Len() int {
+	return b.n
+}
+
+func (b *Buffer) String() string {
+	if !b.strOk {
+		var sb strings.Builder
+		sb.Grow(b.n)
+		for _, p := range b.pieces {
+			sb.WriteString(b.pieceStr(&p, 0, p.n))
+		}
+		b.str = sb.String()
+		b.strOk = true
+
+		// compact: the built string becomes the original buffer
+		if len(b.pieces) > compactPieces {
+			b.orig = b.str
+			b.add = nil
+			b.pieces = []piece{{start: 0, n: b.n}}
+			b.cache.i, b.cache.o = 0, 0
+		}
+	}
+	return b.str
+}
+
+// Compares the text piece by piece, without building the full string.
+func (b *Buffer) EqualString(s string) bool {
+	if len(s) != b.n {
+		return false
+	}
+	if b.strOk {
+		return b.str == s
+	}
+	o := 0
+	for _, p := range b.pieces {
+		if p.add {
+			if string(b.add[p.start:p.start+p.n]) != s[o:o+p.n] {
+				return false
+			}
+		} else if b.orig[p.start:p.start+p.n] != s[o:o+p.n] {
+			return false
+		}
+		o += p.n
+	}
+	return true
+}
+
+// Read only copy of the text, its String can be built in another goroutine.
+type Snapshot struct {
+	orig   string
+	add    []byte // the add buffer is append only, the snapshot range isn't changed by later edits
+	pieces []piece
+	n      int
+}
+
+// Costs a copy of the pieces list.
+func (b *Buffer) Snapshot() *Snapshot {
+	if b.strOk {
+		return &Snapshot{orig: b.str, pieces: []piece{{start: 0, n: b.n}}, n: b.n}
+	}
+	return &Snapshot{
+		orig:   b.orig,
+		add:    b.add[:len(b.add):len(b.add)],
+		pieces: append([]piece(nil), b.pieces...),
+		n:      b.n,
+	}
+}
+
+func (s *Snapshot) String() string {
+	var sb strings.Builder
+	sb.Grow(s.n)
+	for _, p := range s.pieces {
+		if p.add {
+			sb.Write(s.add[p.start : p.start+p.n])
+		} else {
+			sb.WriteString(s.orig[p.start : p.start+p.n])
+		}
+	}
+	return sb.String()
+}
+
+// Number of pieces that triggers a compaction when the full string is built.
+var compactPieces = 1024
+
+func (b *Buffer) Insert(index int, str string) {
+	if index < 0 || index > b.n {
+		panic("index out of range")
+	}
+	if len(str) == 0 {
+		return
+	}
+	b.strOk = false
+	b.addChange(index, index, len(str))
+
+	nl := strings.Count(str, "\n")
+	b.newlines += nl
+	if index < b.anchor.index {
+		b.anchor.index += len(str)
+		b.anchor.line += nl
+	}
+
+	p := piece{add: true, start: len(b.add), n: len(str)}
+	b.add = append(b.add, str...)
+
+	i, k := b.pieceAt(index)
+	if k == 0 {
+		// try to extend previous piece (common case of consecutive typing)
+		if i > 0 {
+			prev := &b.pieces[i-1]
+			if prev.add && prev.start+prev.n == p.start {
+				b.cache.i, b.cache.o = i-1, index-prev.n
+				prev.n += p.n
+				b.n += p.n
+				return
+			}
+		}
+		b.cache.i, b.cache.o = i, index
+		b.insertPieces(i, p)
+	} else {
+		// split piece
+		u := b.pieces[i]
+		left := piece{u.add, u.start, k}
+		right := piece{u.add, u.start + k, u.n - k}
+		b.pieces[i] = left
+		b.cache.i, b.cache.o = i, index-k
+		b.insertPieces(i+1, p, right)
+	}
+	b.n += p.n
+}
+
+func (b *Buffer) Delete(index, index2 int) {
+	if index < 0 || index2 > b.n || index > index2 {
+		panic("index out of range")
+	}
+	if index == index2 {
+		return
+	}
+	b.updateLinesDelete(index, index2)
+	b.strOk = false
+	b.addChange(index, index2, 0)
+
+	i, k := b.pieceAt(index)
+	j, l := b.pieceAt(index2)
+
+	var u []piece
+	if k > 0 {
+		p := b.pieces[i]
+		u = append(u, piece{p.add, p.start, k})
+	}
+	if j < len(b.pieces) && l > 0 {
+		p := b.pieces[j]
+		u = append(u, piece{p.add, p.start + l, p.n - l})
+		j++
+	}
+	b.replacePieces(i, j, u...)
+	b.cache.i, b.cache.o = i, index-k
+	b.n -= index2 - index
+}
+
+// Called before the text is deleted.
+func (b *Buffer) updateLinesDelete(index, index2 int) {
+	a := &b.anchor
+	if index >= a.index {
+		b.newlines -= strings.Count(b.Slice(index, index2), "\n")
+		return
+	}
+	e := index2
+	if e > a.index {
+		e = a.index
+	}
+	nl := strings.Count(b.Slice(index, e), "\n")
+	b.newlines -= nl + strings.Count(b.Slice(e, index2), "\n")
+	a.line -= nl
+	if index2 <= a.index {
+		a.index -= index2 - index
+	} else {
+		a.index = index
+	}
+}
+
+// Number of lines (newlines plus one).
+func (b *Buffer) Lines() int {
+	return b.newlines + 1
+}
+
+// Line (starting at 1) of the index. Counts the newlines from the last queried index, so consecutive queries of close indexes are cheap.
+func (b *Buffer) LineAt(index int) int {
+	if index < 0 || index > b.n {
+		panic("index out of range")
+	}
+	a := &b.anchor
+	if index >= a.index {
+		a.line += strings.Count(b.Slice(a.index, index), "\n")
+	} else if index < a.index-index {
+		// closer to the start of the text
+		a.line = 1 + strings.Count(b.Slice(0, index), "\n")
+	} else {
+		a.line -= strings.Count(b.Slice(index, a.index), "\n")
+	}
+	a.index = index
+	return a.line
+}
+
+// Line and column (runes from the line start) of the index, both starting at 1.
+func (b *Buffer) LineCol(index int) (int, int) {
+	col := utf8.RuneCountInString(b.Slice(b.LineStart(index), index))
+	return b.LineAt(index), col + 1
+}
+
+// Index after the newline before index, or zero.
+func (b *Buffer) LineStart(index int) int {
+	if index < 0 || index > b.n {
+		panic("index out of range")
+	}
+	if b.strOk {
+		return strings.LastIndexByte(b.str[:index], '\n') + 1
+	}
+	for index > 0 {
+		i, k := b.pieceAt(index - 1)
+		p := &b.pieces[i]
+		var j int
+		if p.add {
+			j = bytes.LastIndexByte(b.add[p.start:p.start+k+1], '\n')
+		} else {
+			j = strings.LastIndexByte(b.orig[p.start:p.start+k+1], '\n')
+		}
+		if j >= 0 {
+			return index - k - 1 + j + 1
+		}
+		index -= k + 1
+	}
+	return 0
+}
+
+// Index of the newline at or after index, or the text length.
+func (b *Buffer) LineEnd(index int) int {
+	if index < 0 || index > b.n {
+		panic("index out of range")
+	}
+	if b.strOk {
+		if j := strings.IndexByte(b.str[index:], '\n'); j >= 0 {
+			return index + j
+		}
+		return b.n
+	}
+	for index < b.n {
+		i, k := b.pieceAt(index)
+		p := &b.pieces[i]
+		var j int
+		if p.add {
+			j = bytes.IndexByte(b.add[p.start+k:p.start+p.n], '\n')
+		} else {
+			j = strings.IndexByte(b.orig[p.start+k:p.start+p.n], '\n')
+		}
+		if j >= 0 {
+			return index + j
+		}
+		index += p.n - k
+	}
+	return b.n
+}
+
+// Returns the range changed since the last call, merging all the edits in between.
+func (b *Buffer) TakeChange() (*Change, bool) {
+	if !b.changeOk {
+		return nil, false
+	}
+	c := b.change
+	b.changeOk = false
+	return &c, true
+}
+
+// Replaced [index,index2) of the current text with n bytes.
+func (b *Buffer) addChange(index, index2, n int) {
+	if !b.changeOk {
+		b.change = Change{Index: index, OldN: index2 - index, NewN: n}
+		b.changeOk = true
+		return
+	}
+	c := &b.change
+	s := c.Index
+	if index < s {
+		s = index
+	}
+	e := c.Index + c.NewN
+	if index2 > e {
+		e = index2
+	}
+	oldE := e - (c.NewN - c.OldN)
+	newE := e + n - (index2 - index)
+	*c = Change{Index: s, OldN: oldE - s, NewN: newE - s}
+}
+
+// Returns the text between the indexes without building the full string.
+func (b *Buffer) Slice(index, index2 int) string {
+	if index < 0 || index2 > b.n || index > index2 {
+		panic("index out of range")
+	}
+	if b.strOk {
+		return b.str[index:index2]
+	}
+	if index == index2 {
+		return ""
+	}
+	i, k := b.pieceAt(index)
+	if p := &b.pieces[i]; k+index2-index <= p.n {
+		// inside one piece
+		return b.pieceStr(p, k, k+index2-index)
+	}
+	var sb strings.Builder
+	sb.Grow(index2 - index)
+	for n := index2 - index; n > 0; i, k = i+1, 0 {
+		p := &b.pieces[i]
+		e := p.n
+		if e-k > n {
+			e = k + n
+		}
+		sb.WriteString(b.pieceStr(p, k, e))
+		n -= e - k
+	}
+	return sb.String()
+}
+
+// Returns the rune at index and its size, or size zero at the end of the text.
+func (b *Buffer) ReadRuneAt(index int) (rune, int) {
+	if index < 0 || index > b.n {
+		panic("index out of range")
+	}
+	if b.strOk {
+		return utf8.DecodeRuneInString(b.str[index:])
+	}
+	i, k := b.pieceAt(index)
+	if i == len(b.pieces) {
+		return utf8.RuneError, 0
+	}
+	p := &b.pieces[i]
+	var ru rune
+	var size int
+	if p.add {
+		ru, size = utf8.DecodeRune(b.add[p.start+k : p.start+p.n])
+	} else {
+		ru, size = utf8.DecodeRuneInString(b.orig[p.start+k : p.start+p.n])
+	}
+	if ru == utf8.RuneError && p.n-k < utf8.UTFMax && i+1 < len(b.pieces) {
+		// rune split between pieces
+		e := index + utf8.UTFMax
+		if e > b.n {
+			e = b.n
+		}
+		return utf8.DecodeRuneInString(b.Slice(index, e))
+	}
+	return ru, size
+}
+
+// Returns the rune that ends at index and its size, or size zero at the start of the text.
+func (b *Buffer) ReadLastRuneAt(index int) (rune, int) {
+	if index < 0 || index > b.n {
+		panic("index out of range")
+	}
+	if b.strOk {
+		return utf8.DecodeLastRuneInString(b.str[:index])
+	}
+	if index == 0 {
+		return utf8.RuneError, 0
+	}
+	i, k := b.pieceAt(index - 1)
+	p := &b.pieces[i]
+	var ru rune
+	var size int
+	if p.add {
+		ru, size = utf8.DecodeLastRune(b.add[p.start : p.start+k+1])
+	} else {
+		ru, size = utf8.DecodeLastRuneInString(b.orig[p.start : p.start+k+1])
+	}
+	if ru == utf8.RuneError && k+1 < utf8.UTFMax && i > 0 {
+		// rune split between pieces
+		s := index - utf8.UTFMax
+		if s < 0 {
+			s = 0
+		}
+		return utf8.DecodeLastRuneInString(b.Slice(s, index))
+	}
+	return ru, size
+}
+
+// Returns the piece containing index and the offset inside the piece. At the end of the text, returns len(pieces).
+// Walks from the last piece found, edits and reads are usually close to each other.
+func (b *Buffer) pieceAt(index int) (int, int) {
+	i, o := b.cache.i, b.cache.o
+	if i > len(b.pieces) {
+		i, o = 0, 0
+	}
+	for i > 0 && index < o {
+		i--
+		o -= b.pieces[i].n
+	}
+	for i < len(b.pieces) && index >= o+b.pieces[i].n {
+		o += b.pieces[i].n
+		i++
+	}
+	b.cache.i, b.cache.o = i, o
+	if i == len(b.pieces) {
+		return i, 0
+	}
+	return i, index - o
+}
+func (b *Buffer) pieceStr(p *piece, a, c int) string {
+	if p.add {
+		return string(b.add[p.start+a : p.start+c])
+	}
+	return b.orig[p.start+a : p.start+c]
+}
+func (b *Buffer) insertPieces(i int, u ...piece) {
+	b.replacePieces(i, i, u...)
+}
+func (b *Buffer) replacePieces(i, j int, u ...piece) {
+	d := len(u) - (j - i)
+	n := len(b.pieces)
+	if d > 0 {
+		b.pieces = append(b.pieces, make([]piece, d)...)
+	}
+	copy(b.pieces[j+d:], b.pieces[j:n])
+	copy(b.pieces[i:], u)
+	if d < 0 {
+		b.pieces = b.pieces[:n+d]
+	}
+}
+
+// Text at [Index,Index+OldN) of the previous text was replaced by the text at [Index,Index+NewN) of the current text.
+type Change struct {
+	Index int
+	OldN  int
+	NewN  int
+}
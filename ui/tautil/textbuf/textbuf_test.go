@@ -0,0 +1,206 @@
+package textbuf
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBufferInsertDelete(t *testing.T) {
+	b := NewBuffer("abcdef")
+	b.Insert(3, "123")
+	b.Insert(0, "0")
+	b.Insert(b.Len(), "z")
+	if s := b.String(); s != "0abc123defz" {
+		t.Fatal(s)
+	}
+	b.Delete(2, 6)
+	if s := b.String(); s != "0a3defz" {
+		t.Fatal(s)
+	}
+	b.Delete(0, b.Len())
+	if s := b.String(); s != "" || b.Len() != 0 {
+		t.Fatal(s)
+	}
+}
+
+func TestBufferEqualString(t *testing.T) {
+	b := NewBuffer("abcdef")
+	b.Insert(3, "123")
+	b.Delete(0, 1)
+	if !b.EqualString("bc123def") || b.EqualString("bc123dex") || b.EqualString("bc123de") {
+		t.Fatal()
+	}
+}
+
+func TestBufferSnapshot(t *testing.T) {
+	b := NewBuffer("abcdef")
+	b.Insert(3, "123")
+	s1 := b.Snapshot()
+	b.Insert(0, "0")
+	b.Delete(4, 8)
+	if s := b.String(); s != "0abcef" { // cached full string
+		t.Fatal(s)
+	}
+	s2 := b.Snapshot()
+	b.Insert(1, "x")
+	if s1.String() != "abc123def" || s2.String() != "0abcef" {
+		t.Fatal(s1.String(), s2.String())
+	}
+}
+
+func TestBufferSlice(t *testing.T) {
+	b := NewBuffer("abcdef")
+	b.Insert(3, "123")
+	b.Delete(1, 2)
+	// slice before the full string is built
+	if s := b.Slice(1, 6); s != "c123d" {
+		t.Fatal(s)
+	}
+	if s := b.Slice(3, 3); s != "" {
+		t.Fatal(s)
+	}
+}
+
+func TestBufferRunes(t *testing.T) {
+	b := NewBuffer("aé")
+	b.Insert(1, "ç")
+	ru, size := b.ReadRuneAt(1)
+	if ru != 'ç' || size != 2 {
+		t.Fatal(ru, size)
+	}
+	ru, size = b.ReadLastRuneAt(b.Len())
+	if ru != 'é' || size != 2 {
+		t.Fatal(ru, size)
+	}
+	_, size = b.ReadRuneAt(b.Len())
+	if size != 0 {
+		t.Fatal(size)
+	}
+}
+
+func TestBufferLines(t *testing.T) {
+	b := NewBuffer("a\nb\nc\nd")
+	if n := b.LineAt(6); n != 4 {
+		t.Fatal(n)
+	}
+	b.Insert(0, "\n\n") // before the anchor
+	if n := b.LineAt(8); n != 6 || b.Lines() != 6 {
+		t.Fatal(n, b.Lines())
+	}
+	b.Delete(1, 5) // across the anchor: "\n\nc\nd"
+	if n := b.LineAt(4); n != 4 || b.Lines() != 4 {
+		t.Fatal(n, b.Lines(), b.String())
+	}
+	if n := b.LineAt(0); n != 1 {
+		t.Fatal(n)
+	}
+
+	b = NewBuffer(strings.Repeat("a", 300) + "\n" + strings.Repeat("b", 300))
+	if l, c := b.LineCol(600); l != 2 || c != 300 {
+		t.Fatal(l, c)
+	}
+	if l, c := b.LineCol(299); l != 1 || c != 300 {
+		t.Fatal(l, c)
+	}
+	// rune columns
+	b = NewBuffer("a\nçé=1")
+	if l, c := b.LineCol(6); l != 2 || c != 3 {
+		t.Fatal(l, c)
+	}
+}
+
+func TestBufferRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	str := strings.Repeat("0123456789", 10)
+	b := NewBuffer(str)
+	for i := 0; i < 5000; i++ {
+		a := r.Intn(len(str) + 1)
+		if r.Intn(2) == 0 {
+			s := strings.Repeat(string(rune('a'+r.Intn(26))), r.Intn(5))
+			s = strings.Replace(s, "e", "\n", -1)
+			s = strings.Replace(s, "f", "ç", -1)
+			str = str[:a] + s + str[a:]
+			b.Insert(a, s)
+		} else {
+			c := a + r.Intn(len(str)-a+1)
+			str = str[:a] + str[c:]
+			b.Delete(a, c)
+		}
+		if b.Len() != len(str) {
+			t.Fatalf("%v: len %v != %v", i, b.Len(), len(str))
+		}
+		if i%100 == 0 && b.String() != str {
+			t.Fatalf("%v: %q != %q", i, b.String(), str)
+		}
+		if i%7 == 0 {
+			k := r.Intn(len(str) + 1)
+			if l := b.LineAt(k); l != 1+strings.Count(str[:k], "\n") {
+				t.Fatalf("%v: line at %v: %v", i, k, l)
+			}
+		}
+		if b.Lines() != 1+strings.Count(str, "\n") {
+			t.Fatalf("%v: lines %v", i, b.Lines())
+		}
+		if i%5 == 0 {
+			testBufferRead(t, b, str, r.Intn(len(str)+1))
+		}
+	}
+}
+
+func TestBufferTakeChange(t *testing.T) {
+	b := NewBuffer("0123456789")
+	b.TakeChange()
+	b.Insert(2, "ab")  // 01ab23456789
+	b.Delete(6, 8)     // 01ab236789
+	b.Insert(0, "xyz") // xyz01ab236789
+	c, ok := b.TakeChange()
+	if !ok {
+		t.Fatal("expecting change")
+	}
+	// previous text 0..6 ("012345") replaced by "xyz01ab23"
+	if *c != (Change{Index: 0, OldN: 6, NewN: 9}) {
+		t.Fatalf("%+v", c)
+	}
+	if _, ok := b.TakeChange(); ok {
+		t.Fatal("expecting no change")
+	}
+}
+
+func BenchmarkInsertLargeText(b *testing.B) {
+	buf := NewBuffer(strings.Repeat("0123456789\n", 2*1024*1024))
+	mid := buf.Len() / 2
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Insert(mid+i, "a") // consecutive typing
+	}
+}
+
+// Compares the buffer reads at index with the string.
+func testBufferRead(t *testing.T, b *Buffer, str string, k int) {
+	t.Helper()
+	ru, size := b.ReadRuneAt(k)
+	ru2, size2 := utf8.DecodeRuneInString(str[k:])
+	if ru != ru2 || size != size2 {
+		t.Fatalf("rune at %v: %q %v", k, ru, size)
+	}
+	ru, size = b.ReadLastRuneAt(k)
+	ru2, size2 = utf8.DecodeLastRuneInString(str[:k])
+	if ru != ru2 || size != size2 {
+		t.Fatalf("last rune at %v: %q %v", k, ru, size)
+	}
+	if i := b.LineStart(k); i != strings.LastIndex(str[:k], "\n")+1 {
+		t.Fatalf("line start at %v: %v", k, i)
+	}
+	e := len(str)
+	if j := strings.Index(str[k:], "\n"); j >= 0 {
+		e = k + j
+	}
+	if i := b.LineEnd(k); i != e {
+		t.Fatalf("line end at %v: %v", k, i)
+	}
+	if s := b.Slice(k/2, k); s != str[k/2:k] {
+		t.Fatalf("slice %v %v: %q", k/2, k, s)
+	}
+}
@@ -3,7 +3,6 @@ package tautil
 import (
 	"strings"
 	"unicode"
-	"unicode/utf8"
 )
 
 func isNotSpace(ru rune) bool {
@@ -26,36 +25,55 @@ func updateSelection(ta Texta, on bool, ci int) {
 	}
 }
 
-func NextRuneIndex(str string, index int) (rune, int, bool) {
-	ru, size := utf8.DecodeRuneInString(str[index:])
-	if ru == utf8.RuneError {
-		if size == 0 { // empty string
-			return 0, 0, false
-		}
-		// size==1// invalid encoding, continue with 1
-		ru = rune(str[index+size])
+func NextRuneIndex(ta Texta, index int) (rune, int, bool) {
+	ru, size := ta.ReadRuneAt(index)
+	if size == 0 { // end of text
+		return 0, 0, false
 	}
 	return ru, index + size, true
 }
-func PreviousRuneIndex(str string, index int) (rune, int, bool) {
-	ru, size := utf8.DecodeLastRuneInString(str[:index])
-	if ru == utf8.RuneError {
-		if size == 0 { // empty string
-			return 0, 0, false
-		}
-		// size==1 // invalid encoding, continue with 1
-		ru = rune(str[index-size])
+func PreviousRuneIndex(ta Texta, index int) (rune, int, bool) {
+	ru, size := ta.ReadLastRuneAt(index)
+	if size == 0 { // start of text
+		return 0, 0, false
 	}
 	return ru, index - size, true
 }
 func previousRuneIndexIfLastIsNewline(s string) int {
-	ru, i, ok := PreviousRuneIndex(s, len(s))
-	if ok && ru == '\n' {
-		return i
+	if strings.HasSuffix(s, "\n") {
+		return len(s) - 1
 	}
 	return len(s)
 }
 
+// Index of the first rune from index that satisfies f, or -1.
+func indexFunc(ta Texta, index int, f func(rune) bool) int {
+	for i := index; ; {
+		ru, size := ta.ReadRuneAt(i)
+		if size == 0 {
+			return -1
+		}
+		if f(ru) {
+			return i
+		}
+		i += size
+	}
+}
+
+// Index of the last rune before index that satisfies f, or -1.
+func lastIndexFunc(ta Texta, index int, f func(rune) bool) int {
+	for i := index; ; {
+		ru, size := ta.ReadLastRuneAt(i)
+		if size == 0 {
+			return -1
+		}
+		i -= size
+		if f(ru) {
+			return i
+		}
+	}
+}
+
 func SelectionStringIndexes(ta Texta) (int, int) {
 	if !ta.SelectionOn() {
 		panic("selection should be on")
@@ -76,19 +94,18 @@ func linesStringIndexes(ta Texta) (int, int, bool) {
 		a = ta.CursorIndex()
 		b = a
 	}
-	a = lineStartIndex(ta.Str(), a)
-	b, hasNewline := lineEndIndexNextIndex(ta.Str(), b)
+	a = ta.LineStart(a)
+	b, hasNewline := lineEndNextIndex(ta, b)
 	return a, b, hasNewline
 }
 
-func lineStartIndex(str string, index int) int {
-	i := strings.LastIndex(str[:index], "\n")
-	if i < 0 {
-		i = 0
-	} else {
-		i += 1 // rune length of '\n'
+// Index after the newline at or after index, or the text length.
+func lineEndNextIndex(ta Texta, index int) (_ int, hasNewline bool) {
+	i := ta.LineEnd(index)
+	if i == ta.Len() {
+		return i, false
 	}
-	return i
+	return i + 1, true // 1 is "\n" size
 }
 
 func lineEndIndexNextIndex(str string, index int) (_ int, hasNewline bool) {
@@ -98,3 +115,32 @@ func lineEndIndexNextIndex(str string, index int) (_ int, hasNewline bool) {
 	}
 	return index + i + 1, true // 1 is "\n" size
 }
+
+// Reads the text in chunks, for byte scans that go through large parts of it.
+type textReader struct {
+	ta Texta
+	n  int    // text length
+	s  string // current chunk
+	o  int    // chunk offset
+}
+
+func newTextReader(ta Texta) *textReader {
+	return &textReader{ta: ta, n: ta.Len()}
+}
+
+const textReaderChunk = 4096
+
+func (r *textReader) byteAt(k int) byte {
+	if k < r.o || k >= r.o+len(r.s) {
+		a := k - textReaderChunk/2
+		if a < 0 {
+			a = 0
+		}
+		b := a + textReaderChunk
+		if b > r.n {
+			b = r.n
+		}
+		r.s, r.o = r.ta.Slice(a, b), a
+	}
+	return r.s[k-r.o]
+}
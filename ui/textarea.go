@@ -2,12 +2,16 @@ package ui
 
 import (
 	"image"
+	"sort"
 
 	"github.com/BurntSushi/xgbutil/xcursor"
 	"github.com/jmigpin/editor/drawutil2/hsdrawer"
 	"github.com/jmigpin/editor/drawutil2/loopers"
+	"github.com/jmigpin/editor/drawutil2/syntax"
 	"github.com/jmigpin/editor/imageutil"
+	"github.com/jmigpin/editor/ui/keymap"
 	"github.com/jmigpin/editor/ui/tautil"
+	"github.com/jmigpin/editor/ui/tautil/textbuf"
 	"github.com/jmigpin/editor/uiutil"
 	"github.com/jmigpin/editor/xgbutil/evreg"
 	"github.com/jmigpin/editor/xgbutil/xinput"
@@ -26,22 +30,39 @@ type TextArea struct {
 
 	editHistory   *tautil.EditHistory
 	edit          *tautil.EditHistoryEdit
+	editDepth     int // nested edits are kept in one undo entry
 	buttonPressed bool
 	boundsChange  image.Rectangle
 
-	str         string
+	buf         *textbuf.Buffer
 	cursorIndex int
 	offsetY     fixed.Int26_6
 	selection   struct {
 		on    bool
 		index int // from index to cursorIndex
 	}
+	extraCursors []*tautil.Cursor
+	hRanges      []*loopers.SelectionIndexes
+	folds        []*loopers.SelectionIndexes // sorted, hidden lines
+	brackets     []*loopers.SelectionIndexes // matching brackets at the cursor
+	syntax       struct {
+		h *syntax.Highlighter
+	}
+	block struct {
+		r      *fixed.Rectangle26_6 // rectangular selection being drawn
+		anchor fixed.Point26_6
+	}
+	commentStyle *tautil.CommentStyle
+	indentUnit   string
 
 	Colors                     *hsdrawer.Colors
 	DisableHighlightCursorWord bool
 	DisablePageUpDown          bool
 
-	drawerWidth int
+	drawerWidth    int
+	drawerMeasured bool
+
+	keys keymap.Matcher // pending key sequence
 }
 
 func NewTextArea(ui *UI) *TextArea {
@@ -52,7 +73,9 @@ func NewTextArea(ui *UI) *TextArea {
 	ta.C.PaintFunc = ta.paint
 	ta.C.OnCalcFunc = ta.onContainerCalc
 	ta.EvReg = evreg.NewRegister()
-	ta.editHistory = tautil.NewEditHistory(40)
+	ta.editHistory = tautil.NewEditHistory()
+	ta.buf = textbuf.NewBuffer("")
+	ta.drawer.Text = ta.buf
 
 	r1 := ta.ui.EvReg.Add(xinput.KeyPressEventId,
 		&evreg.Callback{ta.onKeyPress})
@@ -78,12 +101,23 @@ func (ta *TextArea) Bounds() *image.Rectangle {
 }
 
 func (ta *TextArea) drawerMeasure(width int) {
-	if ta.str != ta.drawer.Str || ta.drawerWidth != width {
-		ta.drawer.Str = ta.str
+	change, changed := ta.buf.TakeChange()
+	if ta.syntax.h != nil && changed {
+		ta.syntax.h.Change(ta.buf, change.Index, change.OldN, change.NewN)
+	}
+	if changed && ta.shiftFolds(change) {
+		ta.drawerMeasured = false
+	}
+	ta.drawer.Folds = ta.folds
+	if ta.drawerWidth != width || !ta.drawerMeasured {
 		ta.drawerWidth = width
+		ta.drawerMeasured = true
 
 		max := image.Point{width, 1000000}
 		ta.drawer.Measure(&max)
+	} else if changed {
+		// measure only the lines affected by the change
+		ta.drawer.MeasureChange(change.Index, change.OldN, change.NewN)
 	}
 }
 
@@ -138,8 +172,46 @@ func (ta *TextArea) paint() {
 	d.OffsetY = ta.offsetY
 	d.Colors = ta.Colors
 	d.Selection = ta.getDrawSelection()
+	d.ExtraCursors, d.ExtraSelections = ta.getDrawExtraCursors()
+	d.SelectionBlock = ta.block.r
+	d.HighlightRanges = ta.hRanges
+	d.Syntax = ta.syntax.h
+	d.MatchingBrackets = ta.brackets
+	if ta.block.r != nil {
+		// the block is drawn instead of the lines selections
+		d.Selection = nil
+		d.ExtraSelections = nil
+	}
 	d.Draw(ta.ui.Image(), &ta.C.Bounds)
 }
+func (ta *TextArea) getDrawExtraCursors() ([]int, []*loopers.SelectionIndexes) {
+	var cs []int
+	var ss []*loopers.SelectionIndexes
+	for _, c := range ta.extraCursors {
+		cs = append(cs, c.Index)
+		if c.SelOn && c.SelIndex != c.Index {
+			ss = append(ss, &loopers.SelectionIndexes{Start: c.SelIndex, End: c.Index})
+		}
+	}
+	sort.Ints(cs)
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].Start < ss[j].Start
+	})
+	return cs, ss
+}
+
+// Calculated on cursor and text changes, paint only reads the result.
+func (ta *TextArea) updateMatchingBrackets() {
+	ta.brackets = nil
+	i, j, ok := tautil.MatchingBracket(ta)
+	if !ok {
+		return
+	}
+	if i > j {
+		i, j = j, i
+	}
+	ta.brackets = []*loopers.SelectionIndexes{{Start: i, End: i + 1}, {Start: j, End: j + 1}}
+}
 func (ta *TextArea) getDrawSelection() *loopers.SelectionIndexes {
 	if ta.SelectionOn() {
 		return &loopers.SelectionIndexes{
@@ -150,38 +222,107 @@ func (ta *TextArea) getDrawSelection() *loopers.SelectionIndexes {
 	return nil
 }
 
-func (ta *TextArea) Str() string {
-	if ta.edit != nil {
-		// return edit str while editing
-		return ta.edit.Str()
+// Nil disables the syntax highlighting.
+func (ta *TextArea) SetSyntaxHighlighter(h *syntax.Highlighter) {
+	// pending changes go to the previous highlighter
+	ta.updateStringCache()
+	ta.syntax.h = h
+	if h != nil {
+		h.Update(ta.buf)
+	}
+	ta.updateMatchingBrackets()
+	ta.C.NeedPaint()
+}
+func (ta *TextArea) SyntaxHighlighter() *syntax.Highlighter {
+	return ta.syntax.h
+}
+
+// Nil uses the default comment style.
+func (ta *TextArea) SetCommentStyle(cs *tautil.CommentStyle) {
+	ta.commentStyle = cs
+}
+func (ta *TextArea) CommentStyle() *tautil.CommentStyle {
+	return ta.commentStyle
+}
+
+// Tab or spaces, empty detects the unit from the text.
+func (ta *TextArea) SetIndentUnit(u string) {
+	ta.indentUnit = u
+}
+func (ta *TextArea) IndentUnit() string {
+	return ta.indentUnit
+}
+func (ta *TextArea) InStringOrComment(index int) bool {
+	if ta.syntax.h == nil {
+		return false
 	}
-	return ta.str
+	k := ta.syntax.h.KindAt(index)
+	return k == syntax.String || k == syntax.Comment
+}
+
+// Builds the full string, edits should read with Slice and ReadRuneAt.
+func (ta *TextArea) Str() string {
+	return ta.buf.String()
+}
+
+// Read only copy of the text that can be read in another goroutine.
+func (ta *TextArea) Snapshot() *textbuf.Snapshot {
+	return ta.buf.Snapshot()
+}
+func (ta *TextArea) Len() int {
+	return ta.buf.Len()
+}
+func (ta *TextArea) Slice(a, b int) string {
+	return ta.buf.Slice(a, b)
+}
+func (ta *TextArea) ReadRuneAt(i int) (rune, int) {
+	return ta.buf.ReadRuneAt(i)
+}
+func (ta *TextArea) ReadLastRuneAt(i int) (rune, int) {
+	return ta.buf.ReadLastRuneAt(i)
+}
+func (ta *TextArea) LineStart(i int) int {
+	return ta.buf.LineStart(i)
+}
+func (ta *TextArea) LineEnd(i int) int {
+	return ta.buf.LineEnd(i)
 }
 
 // No events, clears, or undos.
 func (ta *TextArea) SetRawStr(s string) {
-	if s == ta.str {
+	if ta.buf.EqualString(s) {
 		return
 	}
-
-	ta.str = s
-
+	ta.buf.Reset(s)
+	ta.rawStrChanged()
+}
+func (ta *TextArea) rawStrChanged() {
+	ta.SetSelectionBlock(nil)
+	ta.SetHighlightRanges(nil)
 	// ensure valid indexes
 	ta.SetCursorIndex(ta.CursorIndex())
 	ta.SetSelectionIndex(ta.SelectionIndex())
+	for _, c := range ta.extraCursors {
+		c.Index = ta.validIndex(c.Index)
+		c.SelIndex = ta.validIndex(c.SelIndex)
+	}
 
 	ta.updateStringCache()
+	ta.updateMatchingBrackets()
 	ta.C.NeedPaint()
 }
 
 func (ta *TextArea) setStr(s string) {
-	if s == ta.str {
+	if ta.buf.EqualString(s) {
 		return
 	}
-
+	ta.buf.Reset(s)
+	ta.strChanged()
+}
+func (ta *TextArea) strChanged() {
 	oldBounds := ta.C.Bounds
 
-	ta.SetRawStr(s)
+	ta.rawStrChanged()
 
 	ev := &TextAreaSetStrEvent{ta, oldBounds}
 	ta.EvReg.RunCallbacks(TextAreaSetStrEventId, ev)
@@ -190,61 +331,94 @@ func (ta *TextArea) setStr(s string) {
 // TODO: have a set str, and a clear func
 func (ta *TextArea) SetStrClear(str string, clearPosition, clearUndoQ bool) {
 	ta.SetSelectionOff()
+	ta.SetExtraCursors(nil)
 	if clearPosition {
 		ta.SetCursorIndex(0)
 		ta.SetOffsetY(0)
 	}
 	if clearUndoQ {
-		ta.editHistory.ClearQ()
+		ta.editHistory.Clear()
 		ta.setStr(str)
 	} else {
 		// replace string with edit to allow undo
 		ta.EditOpen()
-		ta.EditDelete(0, len(ta.Str()))
+		ta.EditDelete(0, ta.buf.Len())
 		ta.EditInsert(0, str)
 		ta.EditClose()
 	}
 }
 
+// Appends keeping the position and clearing the undo history. The start of the string is trimmed to keep it under maxSize.
+func (ta *TextArea) AppendStrClear(str string, maxSize int) {
+	ta.SetSelectionOff()
+	ta.SetExtraCursors(nil)
+	ta.editHistory.Clear()
+	ta.buf.Insert(ta.buf.Len(), str)
+	if d := ta.buf.Len() - maxSize; d > 0 {
+		ta.buf.Delete(0, d)
+	}
+	ta.strChanged()
+}
+
 func (ta *TextArea) EditOpen() {
-	if ta.edit != nil {
-		panic("edit already exists")
+	ta.editDepth++
+	if ta.editDepth > 1 {
+		return
 	}
-	ta.edit = tautil.NewEditHistoryEdit(ta.Str())
+	ta.edit = tautil.NewEditHistoryEdit(ta.buf)
 }
 func (ta *TextArea) EditInsert(index int, str string) {
 	ta.edit.Insert(index, str)
+	tautil.ShiftCursorsInsert(ta.extraCursors, index, len(str))
 }
 func (ta *TextArea) EditDelete(index, index2 int) {
 	ta.edit.Delete(index, index2)
+	tautil.ShiftCursorsDelete(ta.extraCursors, index, index2)
 }
 func (ta *TextArea) EditClose() {
-	str, strEdit, ok := ta.edit.Close()
+	if ta.editDepth == 0 {
+		panic("edit not open")
+	}
+	ta.editDepth--
+	if ta.editDepth > 0 {
+		return
+	}
+	strEdit, ok := ta.edit.Close()
 	ta.edit = nil
 	if !ok {
 		return
 	}
 	ta.editHistory.PushEdit(strEdit)
-	ta.setStr(str)
+	ta.strChanged()
+}
+
+// Used to keep the undo history when the row is closed and reopened.
+func (ta *TextArea) EditHistory() *tautil.EditHistory {
+	return ta.editHistory
+}
+func (ta *TextArea) SetEditHistory(h *tautil.EditHistory) {
+	ta.editHistory = h
 }
 
-func (ta *TextArea) popUndo() {
-	s, i, ok := ta.editHistory.PopUndo(ta.Str())
+func (ta *TextArea) Undo() {
+	i, ok := ta.editHistory.PopUndo(ta.buf)
 	if !ok {
 		return
 	}
-	ta.setStr(s)
+	ta.strChanged()
 	ta.SetCursorIndex(i)
 	ta.SetSelectionOff()
+	ta.SetExtraCursors(nil)
 }
-func (ta *TextArea) unpopRedo() {
-	s, i, ok := ta.editHistory.UnpopRedo(ta.Str())
+func (ta *TextArea) Redo() {
+	i, ok := ta.editHistory.UnpopRedo(ta.buf)
 	if !ok {
 		return
 	}
-	ta.setStr(s)
+	ta.strChanged()
 	ta.SetCursorIndex(i)
 	ta.SetSelectionOff()
+	ta.SetExtraCursors(nil)
 }
 
 func (ta *TextArea) CursorIndex() int {
@@ -252,22 +426,60 @@ func (ta *TextArea) CursorIndex() int {
 }
 func (ta *TextArea) SetCursorIndex(v int) {
 	v = ta.validIndex(v)
+	ta.unfoldIndex(v)
 	if v != ta.cursorIndex {
 		ta.cursorIndex = v
+		ta.SetSelectionBlock(nil)
 		ta.validateSelection()
-		ta.makeIndexVisible(v)
+		if ta.editDepth == 0 {
+			// multiple cursors edits make the primary cursor visible at the end
+			ta.MakeIndexVisible(v)
+			ta.updateMatchingBrackets()
+		}
 		ta.C.NeedPaint()
+		ta.cursorChanged()
+	}
+}
+
+func (ta *TextArea) ExtraCursors() []*tautil.Cursor {
+	return ta.extraCursors
+}
+func (ta *TextArea) SetExtraCursors(cs []*tautil.Cursor) {
+	ta.SetSelectionBlock(nil)
+	if len(cs) == 0 && len(ta.extraCursors) == 0 {
+		return
+	}
+	ta.extraCursors = cs
+	ta.C.NeedPaint()
+}
+
+// Highlighted ranges (sorted), cleared when the string changes.
+func (ta *TextArea) SetHighlightRanges(u []*loopers.SelectionIndexes) {
+	if len(u) == 0 && len(ta.hRanges) == 0 {
+		return
 	}
+	ta.hRanges = u
+	ta.C.NeedPaint()
+}
+func (ta *TextArea) SetSelectionBlock(r *fixed.Rectangle26_6) {
+	if r == nil && ta.block.r == nil {
+		return
+	}
+	ta.block.r = r
+	ta.C.NeedPaint()
 }
 func (ta *TextArea) SelectionIndex() int {
 	return ta.selection.index
 }
 func (ta *TextArea) SetSelectionIndex(v int) {
 	v = ta.validIndex(v)
+	ta.unfoldIndex(v)
 	if v != ta.selection.index {
 		ta.selection.index = v
+		ta.SetSelectionBlock(nil)
 		ta.validateSelection()
 		ta.C.NeedPaint()
+		ta.cursorChanged()
 	}
 }
 func (ta *TextArea) SetSelection(si, ci int) {
@@ -285,15 +497,23 @@ func (ta *TextArea) SetSelectionOff() {
 func (ta *TextArea) setSelectionOn(v bool) {
 	if v != ta.selection.on {
 		ta.selection.on = v
+		ta.SetSelectionBlock(nil)
 		ta.C.NeedPaint()
+		ta.cursorChanged()
 	}
 }
 
+// Emits the event of a cursor or selection change.
+func (ta *TextArea) cursorChanged() {
+	ev := &TextAreaSetCursorIndexEvent{ta}
+	ta.EvReg.RunCallbacks(TextAreaSetCursorIndexEventId, ev)
+}
+
 func (ta *TextArea) validIndex(v int) int {
 	if v < 0 {
 		v = 0
-	} else if v > len(ta.Str()) {
-		v = len(ta.Str())
+	} else if v > ta.buf.Len() {
+		v = ta.buf.Len()
 	}
 	return v
 }
@@ -308,6 +528,16 @@ func (ta *TextArea) somethingSelected() bool {
 	return si != ci
 }
 
+// Number of lines of the text.
+func (ta *TextArea) Lines() int {
+	return ta.buf.Lines()
+}
+
+// Line and column (runes from the line start) of the index, both starting at 1.
+func (ta *TextArea) IndexLineCol(index int) (int, int) {
+	return ta.buf.LineCol(ta.validIndex(index))
+}
+
 func (ta *TextArea) OffsetY() fixed.Int26_6 {
 	return ta.offsetY
 }
@@ -335,7 +565,8 @@ func (ta *TextArea) SetOffsetIndex(i int) {
 	p := ta.drawer.GetPoint(i)
 	ta.SetOffsetY(p.Y)
 }
-func (ta *TextArea) makeIndexVisible(index int) {
+func (ta *TextArea) MakeIndexVisible(index int) {
+	ta.unfoldIndex(index)
 	y0 := ta.OffsetY()
 	y1 := y0 + fixed.I(ta.C.Bounds.Dy())
 
@@ -365,6 +596,7 @@ func (ta *TextArea) makeIndexVisible(index int) {
 }
 
 func (ta *TextArea) MakeIndexVisibleAtCenter(index int) {
+	ta.unfoldIndex(index)
 	// set at half bounds
 	p0 := ta.drawer.GetPoint(index).Y
 	half := fixed.I(ta.C.Bounds.Dy() / 2)
@@ -407,10 +639,139 @@ func (ta *TextArea) LineHeight() fixed.Int26_6 {
 func (ta *TextArea) IndexPoint(i int) *fixed.Point26_6 {
 	return ta.drawer.GetPoint(i)
 }
+
+// A fold placeholder line gives the fold start index.
 func (ta *TextArea) PointIndex(p *fixed.Point26_6) int {
 	return ta.drawer.GetIndex(p)
 }
 
+func (ta *TextArea) Folds() []*loopers.SelectionIndexes {
+	return ta.folds
+}
+
+// Hides the lines in [a,b) (whole lines) behind a placeholder line. Folds inside the range are replaced.
+func (ta *TextArea) Fold(a, b int) {
+	if a < 0 || b > ta.buf.Len() || a >= b {
+		return
+	}
+	var u []*loopers.SelectionIndexes
+	for _, f := range ta.folds {
+		if f.Start >= a && f.End <= b {
+			continue // inside
+		}
+		if f.End > a && f.Start < b {
+			return // partial overlap
+		}
+		u = append(u, f)
+	}
+	u = append(u, &loopers.SelectionIndexes{Start: a, End: b})
+	sort.Slice(u, func(i, j int) bool {
+		return u[i].Start < u[j].Start
+	})
+	ta.folds = u
+
+	// cursors can't be hidden
+	ta.SetExtraCursors(nil)
+	if ci := ta.CursorIndex(); ci >= a && ci < b {
+		ta.SetSelectionOff()
+		ta.foldsChanged()
+		// end of the line before the fold
+		if a > 0 {
+			ta.SetCursorIndex(a - 1)
+		} else {
+			ta.SetCursorIndex(b)
+		}
+		return
+	}
+	ta.foldsChanged()
+}
+
+// Unfolds the fold after the index line, or folds the index line block.
+func (ta *TextArea) ToggleFold(index int) {
+	le := ta.buf.LineEnd(index)
+	if le < ta.buf.Len() {
+		le++ // next line start
+	}
+	for i, f := range ta.folds {
+		if f.Start == le {
+			ta.folds = append(ta.folds[:i:i], ta.folds[i+1:]...)
+			ta.foldsChanged()
+			return
+		}
+	}
+	if a, b, ok := tautil.FoldRange(ta, index); ok {
+		ta.Fold(a, b)
+	}
+}
+func (ta *TextArea) FoldAll() {
+	for _, r := range tautil.FoldAllRanges(ta) {
+		ta.Fold(r[0], r[1])
+	}
+}
+func (ta *TextArea) UnfoldAll() {
+	if len(ta.folds) == 0 {
+		return
+	}
+	ta.folds = nil
+	ta.foldsChanged()
+}
+
+func (ta *TextArea) unfoldIndex(index int) {
+	if len(ta.folds) == 0 {
+		return
+	}
+	// pending changes shift the folds
+	ta.updateStringCache()
+
+	var u []*loopers.SelectionIndexes
+	for _, f := range ta.folds {
+		if index >= f.Start && index < f.End {
+			continue
+		}
+		u = append(u, f)
+	}
+	if len(u) != len(ta.folds) {
+		ta.folds = u
+		ta.foldsChanged()
+	}
+}
+
+// Folds touched by the change are removed. Returns true if the folds changed.
+func (ta *TextArea) shiftFolds(c *textbuf.Change) bool {
+	if len(ta.folds) == 0 {
+		return false
+	}
+	var u []*loopers.SelectionIndexes
+	for _, f := range ta.folds {
+		if f.End <= c.Index {
+			u = append(u, f)
+		} else if f.Start > c.Index+c.OldN {
+			d := c.NewN - c.OldN
+			u = append(u, &loopers.SelectionIndexes{Start: f.Start + d, End: f.End + d})
+		}
+	}
+	removed := len(u) != len(ta.folds)
+	ta.folds = u
+	return removed
+}
+
+// Measures the whole text again (ex: after a tab width change).
+func (ta *TextArea) Remeasure() {
+	ta.drawerMeasured = false
+	ta.updateStringCache()
+	ta.C.NeedPaint()
+
+	ev := &TextAreaRemeasureEvent{ta}
+	ta.EvReg.RunCallbacks(TextAreaRemeasureEventId, ev)
+}
+
+func (ta *TextArea) foldsChanged() {
+	ta.Remeasure()
+
+	ev := &TextAreaFoldsChangeEvent{ta}
+	ta.EvReg.RunCallbacks(TextAreaFoldsChangeEventId, ev)
+}
+
 func (ta *TextArea) PageUp() {
 	if ta.DisablePageUpDown {
 		return
@@ -433,7 +794,12 @@ func (ta *TextArea) onButtonPress(ev0 interface{}) {
 	ta.buttonPressed = true
 	switch {
 	case ev.Button.Button(1):
+		tautil.ClearExtraCursors(ta)
 		switch {
+		case ev.Button.Mods.IsMod1():
+			// start rectangular selection
+			ta.block.anchor = *ta.textPoint(ev.Point)
+			tautil.SelectBlock(ta, &ta.block.anchor, &ta.block.anchor)
 		case ev.Button.Mods.IsShift():
 			tautil.MoveCursorToPoint(ta, ev.Point, true)
 		default:
@@ -458,8 +824,11 @@ func (ta *TextArea) onMotionNotify(ev0 interface{}) {
 		return
 	}
 	ev := ev0.(*xinput.MotionNotifyEvent)
-	if ev.Mods.IsButton(1) {
+	switch {
+	case ev.Mods.IsButton(1):
 		tautil.MoveCursorToPoint(ta, ev.Point, true)
+	case ev.Mods.IsButtonAndMod1(1):
+		tautil.SelectBlock(ta, &ta.block.anchor, ta.textPoint(ev.Point))
 	}
 }
 func (ta *TextArea) onButtonRelease(ev0 interface{}) {
@@ -495,11 +864,16 @@ func (ta *TextArea) onButtonRelease(ev0 interface{}) {
 	}
 }
 
-func (ta *TextArea) PointIndexInsideSelection(p *image.Point) bool {
+// Converts a screen point to text coordinates.
+func (ta *TextArea) textPoint(p *image.Point) *fixed.Point26_6 {
 	p2 := p.Sub(ta.Bounds().Min)
 	p3 := fixed.P(p2.X, p2.Y)
 	p3.Y += ta.OffsetY()
-	i := ta.PointIndex(&p3)
+	return &p3
+}
+
+func (ta *TextArea) PointIndexInsideSelection(p *image.Point) bool {
+	i := ta.PointIndex(ta.textPoint(p))
 	s, e := ta.SelectionIndex(), ta.CursorIndex()
 	if s > e {
 		s, e = e, s
@@ -545,10 +919,15 @@ func (ta *TextArea) onKeyPress(ev0 interface{}) {
 	}
 
 	k := ev.Key
-	firstKeysym := k.FirstKeysym()
-	mods := k.Mods.ClearButtons()
 
-	switch firstKeysym {
+	// allow callbacks to override the default handling
+	ev2 := &TextAreaKeyPressEvent{TextArea: ta, Key: k}
+	ta.EvReg.RunCallbacks(TextAreaKeyPressEventId, ev2)
+	if ev2.Handled {
+		return
+	}
+
+	switch k.FirstKeysym() {
 	case xinput.XKAltL,
 		xinput.XKIsoLevel3Shift,
 		xinput.XKShiftL,
@@ -560,132 +939,46 @@ func (ta *TextArea) onKeyPress(ev0 interface{}) {
 		xinput.XKSuperL,
 		xinput.XKInsert:
 		// ignore these
-	case xinput.XKRight:
-		switch {
-		case mods.IsControlShift():
-			tautil.MoveCursorJumpRight(ta, true)
-		case mods.IsControl():
-			tautil.MoveCursorJumpRight(ta, false)
-		case mods.IsShift():
-			tautil.MoveCursorRight(ta, true)
-		case mods.IsNone():
-			tautil.MoveCursorRight(ta, false)
-		}
-	case xinput.XKLeft:
-		switch {
-		case mods.IsControlShift():
-			tautil.MoveCursorJumpLeft(ta, true)
-		case mods.IsControl():
-			tautil.MoveCursorJumpLeft(ta, false)
-		case mods.IsShift():
-			tautil.MoveCursorLeft(ta, true)
-		case mods.IsNone():
-			tautil.MoveCursorLeft(ta, false)
-		}
-	case xinput.XKUp:
-		switch {
-		case mods.IsControlMod1():
-			tautil.MoveLineUp(ta)
-		case mods.IsShift():
-			tautil.MoveCursorUp(ta, true)
-		case mods.IsNone():
-			tautil.MoveCursorUp(ta, false)
-		}
-	case xinput.XKDown:
-		switch {
-		case mods.IsControlShiftMod1():
-			tautil.DuplicateLines(ta)
-		case mods.IsControlMod1():
-			tautil.MoveLineDown(ta)
-		case mods.IsShift():
-			tautil.MoveCursorDown(ta, true)
-		case mods.IsNone():
-			tautil.MoveCursorDown(ta, false)
-		}
-	case xinput.XKHome:
-		switch {
-		case mods.IsControlShift():
-			tautil.StartOfString(ta, true)
-		case mods.IsControl():
-			tautil.StartOfString(ta, false)
-		case mods.IsShift():
-			tautil.StartOfLine(ta, true)
-		case mods.IsNone():
-			tautil.StartOfLine(ta, false)
-		}
-	case xinput.XKEnd:
-		switch {
-		case mods.IsControlShift():
-			tautil.EndOfString(ta, true)
-		case mods.IsControl():
-			tautil.EndOfString(ta, false)
-		case mods.IsShift():
-			tautil.EndOfLine(ta, true)
-		case mods.IsNone():
-			tautil.EndOfLine(ta, false)
-		}
-	case xinput.XKBackspace:
-		tautil.Backspace(ta)
-	case xinput.XKDelete:
-		switch {
-		case mods.IsNone():
-			tautil.Delete(ta)
-		}
-	case xinput.XKPageUp:
-		switch {
-		case mods.IsNone():
-			ta.PageUp()
-		}
-	case xinput.XKPageDown:
-		switch {
-		case mods.IsNone():
-			ta.PageDown()
-		}
-	case xinput.XKTab:
-		switch {
-		case mods.IsNone():
-			tautil.TabRight(ta)
-		case mods.IsShift():
-			tautil.TabLeft(ta)
+		return
+	}
+
+	action, r := ta.keys.Match(TextAreaKeymap, k.Chord())
+	switch r {
+	case keymap.Match:
+		ta.RunAction(action)
+	case keymap.NoMatch:
+		switch ks := k.FirstKeysym(); ks {
+		case xinput.XKBackspace, xinput.XKEscape, xinput.XKSpace:
+			// unbound modifiers combos run the key binding without modifiers
+			var m keymap.Matcher
+			if action, r := m.Match(TextAreaKeymap, xinput.Chord{Keysym: ks}); r == keymap.Match {
+				ta.RunAction(action)
+			} else if ks == xinput.XKSpace {
+				tautil.InsertString(ta, " ")
+			}
+			return
 		}
-	case xinput.XKReturn:
-		switch {
-		case mods.IsNone():
-			tautil.AutoIndent(ta)
+
+		// keys without bindings insert their rune
+		mods := k.Mods.ClearButtons()
+		if mods.IsControl() || mods.IsControlShift() {
+			break
 		}
-	case xinput.XKSpace:
-		tautil.InsertString(ta, " ")
-	default:
-		// shortcuts with printable runes
-		switch {
-		case mods.IsControlShift():
-			switch firstKeysym {
-			case 'd':
-				tautil.Uncomment(ta)
-			case 'z':
-				ta.unpopRedo()
-			}
-		case mods.IsControl():
-			switch firstKeysym {
-			case 'd':
-				tautil.Comment(ta)
-			case 'c':
-				tautil.Copy(ta)
-			case 'x':
-				tautil.Cut(ta)
-			case 'v':
-				tautil.PasteClipboard(ta)
-			case 'k':
-				tautil.RemoveLines(ta)
-			case 'a':
-				tautil.SelectAll(ta)
-			case 'z':
-				ta.popUndo()
-			}
-		default: // all other modifier combos
-			ta.insertKeyRune(k)
+		if ks := k.FirstKeysym(); ks >= 0xff00 && ks <= 0xffff {
+			break // function keys
 		}
+		ta.insertKeyRune(k)
+	}
+}
+
+// Runs a textarea action, or emits the action in an event to be handled externally.
+func (ta *TextArea) RunAction(action string) {
+	if fn, ok := textAreaActions[action]; ok {
+		fn(ta)
+		return
 	}
+	ev := &TextAreaKeyActionEvent{ta, action}
+	ta.EvReg.RunCallbacks(TextAreaKeyActionEventId, ev)
 }
 func (ta *TextArea) insertKeyRune(k *xinput.Key) {
 	// print rune from keysym table (takes into consideration the modifiers)
@@ -699,6 +992,8 @@ func (ta *TextArea) insertKeyRune(k *xinput.Key) {
 		tautil.InsertString(ta, "´")
 	case xinput.XKGrave:
 		tautil.InsertString(ta, "`")
+	case '}', ')', ']':
+		tautil.InsertClosingBracket(ta, byte(ks))
 	default:
 		tautil.InsertString(ta, string(rune(ks)))
 	}
@@ -713,6 +1008,10 @@ const (
 	TextAreaSetOffsetYEventId
 	TextAreaBoundsChangeEventId
 	TextAreaSetCursorIndexEventId
+	TextAreaKeyPressEventId
+	TextAreaFoldsChangeEventId
+	TextAreaKeyActionEventId
+	TextAreaRemeasureEventId
 )
 
 type TextAreaCmdEvent struct {
@@ -728,3 +1027,21 @@ type TextAreaSetOffsetYEvent struct {
 type TextAreaBoundsChangeEvent struct {
 	TextArea *TextArea
 }
+type TextAreaSetCursorIndexEvent struct {
+	TextArea *TextArea
+}
+type TextAreaFoldsChangeEvent struct {
+	TextArea *TextArea
+}
+type TextAreaRemeasureEvent struct {
+	TextArea *TextArea
+}
+type TextAreaKeyActionEvent struct {
+	TextArea *TextArea
+	Action   string
+}
+type TextAreaKeyPressEvent struct {
+	TextArea *TextArea
+	Key      *xinput.Key
+	Handled  bool // set by callbacks to skip the default handling
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 
 	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
 
 	"github.com/jmigpin/editor/imageutil"
 	"github.com/jmigpin/editor/ui/tautil"
@@ -24,6 +25,8 @@ var (
 	ScrollbarWidth = 10
 	SquareWidth    = 10
 	ScrollbarLeft  = false
+
+	ShowLineNumbers = false
 )
 
 func SetScrollbarAndSquareWidth(v int) {
@@ -34,6 +37,7 @@ func SetScrollbarAndSquareWidth(v int) {
 type UI struct {
 	win       *Window
 	Layout    *Layout
+	Popup     *Popup
 	fface1    font.Face
 	CursorMan *CursorMan
 
@@ -61,6 +65,7 @@ func NewUI(fface font.Face) (*UI, error) {
 	ui.CursorMan = NewCursorMan(ui)
 
 	ui.Layout = NewLayout(ui)
+	ui.Popup = NewPopup(ui)
 
 	ui.EvReg.Add(xproto.Expose,
 		&evreg.Callback{ui.onExpose})
@@ -70,6 +75,8 @@ func NewUI(fface font.Face) (*UI, error) {
 		&evreg.Callback{ui.onTextAreaAppendAsync})
 	ui.EvReg.Add(UITextAreaInsertStringAsyncEventId,
 		&evreg.Callback{ui.onTextAreaInsertStringAsync})
+	ui.EvReg.Add(UIRunFuncEventId,
+		&evreg.Callback{ui.onRunFunc})
 
 	return ui, nil
 }
@@ -99,11 +106,19 @@ func (ui *UI) UpdateImageSize() {
 
 func (ui *UI) PaintIfNeeded() (painted bool) {
 	if ui.incompleteDraws == 0 {
+		var rects []*image.Rectangle
 		ui.Layout.C.PaintIfNeeded(func(r *image.Rectangle) {
+			rects = append(rects, r)
+		})
+		// popup is painted over the layout
+		if ui.Popup.paintIfNeeded(rects) {
+			rects = append(rects, &ui.Popup.bounds)
+		}
+		for _, r := range rects {
 			painted = true
 			ui.incompleteDraws++
 			ui.win.PutImage(r)
-		})
+		}
 	}
 	return painted
 }
@@ -122,6 +137,25 @@ func (ui *UI) FillRectangle(r *image.Rectangle, c color.Color) {
 	imageutil.FillRectangle(ui.Image(), r, c)
 }
 
+// Draws the string with the default fontface at the pen position (baseline), clipped to the bounds.
+func (ui *UI) DrawString(bounds *image.Rectangle, pen fixed.Point26_6, s string, fg color.Color) {
+	face := ui.FontFace()
+	img := ui.Image()
+	for _, ru := range s {
+		dr, mask, maskp, adv, ok := face.Glyph(pen, ru)
+		pen.X += adv
+		if !ok {
+			continue
+		}
+		r := dr.Intersect(*bounds)
+		if r.Empty() {
+			continue
+		}
+		maskp = maskp.Add(r.Min.Sub(dr.Min))
+		imageutil.DrawUniformMask(img, &r, fg, mask, maskp, draw.Over)
+	}
+}
+
 // Default fontface (used by textarea)
 func (ui *UI) FontFace() font.Face {
 	return ui.fface1
@@ -246,19 +280,16 @@ func (ui *UI) TextAreaAppendAsync(ta *TextArea, str string) {
 }
 func (ui *UI) onTextAreaAppendAsync(ev0 interface{}) {
 	ev := ev0.(*UITextAreaAppendAsyncEvent)
-	ta := ev.TextArea
-	str := ev.Str
+	ui.TextAreaAppend(ev.TextArea, ev.Str)
+}
 
+// Same as TextAreaAppendAsync, in the event loop.
+func (ui *UI) TextAreaAppend(ta *TextArea, str string) {
 	// max size for appends
 	maxSize := 5 * 1024 * 1024
-	str2 := ta.Str() + str
-	if len(str2) > maxSize {
-		d := len(str2) - maxSize
-		str2 = str2[d:]
-	}
 
-	// false,true = keep pos, but clear undo for massive savings
-	ta.SetStrClear(str2, false, true)
+	// keep pos, but clear undo for massive savings
+	ta.AppendStrClear(str, maxSize)
 }
 
 func (ui *UI) TextAreaInsertStringAsync(ta *TextArea, str string) {
@@ -270,9 +301,18 @@ func (ui *UI) onTextAreaInsertStringAsync(ev0 interface{}) {
 	tautil.InsertString(ev.TextArea, ev.Str)
 }
 
+// Runs the function in the event loop (ex: results from other goroutines).
+func (ui *UI) EnqueueFunc(fn func()) {
+	ui.EvReg.Enqueue(UIRunFuncEventId, fn)
+}
+func (ui *UI) onRunFunc(ev0 interface{}) {
+	ev0.(func())()
+}
+
 const (
 	UITextAreaAppendAsyncEventId = evreg.UIEventIdStart + iota
 	UITextAreaInsertStringAsyncEventId
+	UIRunFuncEventId
 )
 
 type UITextAreaAppendAsyncEvent struct {
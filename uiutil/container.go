@@ -192,3 +192,22 @@ func (c *Container) NeedPaint() {
 		c2.childNeedsPaint = true
 	}
 }
+
+// Marks the containers that intersect the rectangle to be painted (ex: area under an overlay).
+func (c *Container) NeedPaintRect(r image.Rectangle) {
+	if c.FirstChild == nil {
+		c.NeedPaint()
+		return
+	}
+	for child := c.FirstChild; child != nil; child = child.NextSibling {
+		if !child.Bounds.Overlaps(r) {
+			continue
+		}
+		if child.FirstChild == nil && child.PaintFunc == nil {
+			// area painted by the parent
+			c.NeedPaint()
+			return
+		}
+		child.NeedPaintRect(r)
+	}
+}
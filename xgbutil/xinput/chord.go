@@ -0,0 +1,122 @@
+package xinput
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// Key with modifiers, written as "ctrl+shift+a".
+type Chord struct {
+	Mods   Modifiers // only shift, ctrl, mod1, mod4 and mod5
+	Keysym xproto.Keysym
+}
+
+// Chord of the key using the first keysym column (ex: shift+a, not A).
+func (k *Key) Chord() Chord {
+	m := k.Mods.ClearButtons().clearLock().clearMod2()
+	return Chord{Mods: m, Keysym: k.FirstKeysym()}
+}
+
+func ParseChord(s string) (Chord, error) {
+	var c Chord
+	u := strings.Split(strings.ToLower(strings.TrimSpace(s)), "+")
+	// allow "ctrl++"
+	if len(u) >= 2 && u[len(u)-1] == "" && u[len(u)-2] == "" {
+		u = append(u[:len(u)-2], "+")
+	}
+	for i, w := range u {
+		if i < len(u)-1 {
+			m, ok := chordMods[w]
+			if !ok {
+				return c, fmt.Errorf("chord %q: unknown modifier: %v", s, w)
+			}
+			c.Mods |= Modifiers(m)
+			continue
+		}
+		ks, ok := chordKeysym(w)
+		if !ok {
+			return c, fmt.Errorf("chord %q: unknown key: %v", s, w)
+		}
+		c.Keysym = ks
+	}
+	return c, nil
+}
+
+func (c Chord) String() string {
+	var u []string
+	for _, name := range []string{"ctrl", "shift", "alt", "super", "altgr"} {
+		if c.Mods.Has(chordMods[name]) {
+			u = append(u, name)
+		}
+	}
+	u = append(u, keysymName(c.Keysym))
+	return strings.Join(u, "+")
+}
+
+var chordMods = map[string]int{
+	"ctrl":    xproto.KeyButMaskControl,
+	"control": xproto.KeyButMaskControl,
+	"shift":   xproto.KeyButMaskShift,
+	"alt":     xproto.KeyButMaskMod1,
+	"mod1":    xproto.KeyButMaskMod1,
+	"super":   xproto.KeyButMaskMod4,
+	"mod4":    xproto.KeyButMaskMod4,
+	"altgr":   xproto.KeyButMaskMod5,
+	"mod5":    xproto.KeyButMaskMod5,
+}
+
+var keysymNames = map[string]xproto.Keysym{
+	"space":     XKSpace,
+	"backspace": XKBackspace,
+	"tab":       XKTab,
+	"return":    XKReturn,
+	"enter":     XKReturn,
+	"delete":    XKDelete,
+	"insert":    XKInsert,
+	"escape":    XKEscape,
+	"esc":       XKEscape,
+	"left":      XKLeft,
+	"up":        XKUp,
+	"right":     XKRight,
+	"down":      XKDown,
+	"pageup":    XKPageUp,
+	"pagedown":  XKPageDown,
+	"home":      XKHome,
+	"end":       XKEnd,
+}
+
+func chordKeysym(w string) (xproto.Keysym, bool) {
+	if ks, ok := keysymNames[w]; ok {
+		return ks, true
+	}
+	// function keys
+	if len(w) > 1 && w[0] == 'f' {
+		if n, err := strconv.Atoi(w[1:]); err == nil && n >= 1 && n <= 12 {
+			return xproto.Keysym(XKF1 + n - 1), true
+		}
+	}
+	// printable latin1 keys have the same keysym code
+	ru := []rune(w)
+	if len(ru) == 1 && ru[0] > ' ' && ru[0] <= 0xff {
+		return xproto.Keysym(ru[0]), true
+	}
+	return 0, false
+}
+
+func keysymName(ks xproto.Keysym) string {
+	if ks >= XKF1 && ks < XKF1+12 {
+		return fmt.Sprintf("f%d", ks-XKF1+1)
+	}
+	for _, name := range []string{"space", "backspace", "tab", "return", "delete", "insert", "escape", "left", "up", "right", "down", "pageup", "pagedown", "home", "end"} {
+		if keysymNames[name] == ks {
+			return name
+		}
+	}
+	if ks > ' ' && ks <= 0xff {
+		return string(rune(ks))
+	}
+	return fmt.Sprintf("0x%x", int(ks))
+}
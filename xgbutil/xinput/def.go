@@ -13,6 +13,7 @@ const (
 	XKReturn    = 0xff0d
 	XKDelete    = 0xffff
 	XKInsert    = 0xff63
+	XKEscape    = 0xff1b
 	//XKLinefeed = 0xff0a
 
 	XKLeft     = 0xff51
@@ -39,6 +40,8 @@ const (
 	XKHome = 0xff50
 	XKEnd  = 0xff57
 
+	XKF1 = 0xffbe // F2..F12 follow
+
 	XKAsciiTilde  = 0xfe53 // 0x072
 	XKAsciiCircum = 0xfe52 // 0x05e
 	XKAcute       = 0xfe51 // 0x0b4
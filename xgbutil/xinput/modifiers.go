@@ -146,6 +146,9 @@ func (m Modifiers) IsButtonAndShift(b int) bool {
 func (m Modifiers) IsButtonAndControl(b int) bool {
 	return m.IsButtonAnd(b, xproto.KeyButMaskControl)
 }
+func (m Modifiers) IsButtonAndMod1(b int) bool {
+	return m.IsButtonAnd(b, xproto.KeyButMaskMod1)
+}
 func (m Modifiers) HasButton(b int) bool {
 	return m.Has(buttonMask(b))
 }